@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/caarlos0/env"
+	"github.com/vukit/gomac/internal/accrualmock"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"golang.org/x/sync/errgroup"
+)
+
+func main() {
+	mLogger := logger.NewLogger(os.Stderr)
+
+	mConfig := accrualmock.Config{}
+	flag.StringVar(&mConfig.RunAddress, "a", "localhost:7070", "run address")
+	flag.StringVar(&mConfig.Statuses, "s", "REGISTERED,PROCESSING,PROCESSED", "status progression")
+	flag.DurationVar(&mConfig.Step, "step", time.Second, "time between status changes")
+	flag.BoolVar(&mConfig.ProgressOnQuery, "progress-on-query", false, "change status on every query instead of by time")
+	flag.StringVar(&mConfig.Rules, "rules", "", "accrual rules, e.g. '^1=500;3$=INVALID;^9=NONE'")
+	flag.Float64Var(&mConfig.DefaultAccrual, "default-accrual", 100, "accrual for numbers matching no rule")
+	flag.DurationVar(&mConfig.Latency, "latency", 0, "response latency")
+	flag.DurationVar(&mConfig.Jitter, "jitter", 0, "random latency added to each response")
+	flag.IntVar(&mConfig.RateLimit, "rate-limit", 0, "requests per minute before 429, 0 is unlimited")
+	flag.Float64Var(&mConfig.ThrottleRatio, "throttle-ratio", 0, "share of requests randomly answered with 429")
+	flag.DurationVar(&mConfig.RetryAfter, "retry-after", time.Minute, "Retry-After for randomly throttled requests")
+	flag.Parse()
+
+	err := env.Parse(&mConfig)
+	if err != nil {
+		mLogger.Panic(err.Error())
+	}
+
+	mServer, err := accrualmock.NewServer(mConfig)
+	if err != nil {
+		mLogger.Panic(err.Error())
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+
+	httpServer := &http.Server{Addr: mConfig.RunAddress, Handler: mServer.Handler()}
+
+	errGroup, errGroupCtx := errgroup.WithContext(ctx)
+
+	errGroup.Go(func() error {
+		return httpServer.ListenAndServe()
+	})
+
+	errGroup.Go(func() error {
+		<-errGroupCtx.Done()
+
+		return httpServer.Shutdown(context.Background())
+	})
+
+	if err := errGroup.Wait(); err != nil {
+		mLogger.Info(err.Error())
+	}
+
+	cancel()
+}
@@ -0,0 +1,9 @@
+package main_test
+
+import (
+	"testing"
+)
+
+func TestMain(t *testing.T) {
+	t.Skip() // проверятся тестами пакета internal/accrualmock
+}
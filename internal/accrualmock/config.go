@@ -0,0 +1,111 @@
+package accrualmock
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StatusRegistered = "REGISTERED"
+	StatusProcessing = "PROCESSING"
+	StatusProcessed  = "PROCESSED"
+	StatusInvalid    = "INVALID"
+
+	ruleInvalid       = "INVALID"
+	ruleNotRegistered = "NONE"
+)
+
+var (
+	ErrInvalidStatuses = errors.New("status progression must end with PROCESSED")
+	ErrInvalidRule     = errors.New("invalid accrual rule")
+)
+
+type Config struct {
+	RunAddress      string        `env:"RUN_ADDRESS"`
+	Statuses        string        `env:"ACCRUAL_STATUSES"`
+	Step            time.Duration `env:"ACCRUAL_STEP"`
+	Rules           string        `env:"ACCRUAL_RULES"`
+	DefaultAccrual  float64       `env:"ACCRUAL_DEFAULT"`
+	Latency         time.Duration `env:"ACCRUAL_LATENCY"`
+	Jitter          time.Duration `env:"ACCRUAL_JITTER"`
+	RateLimit       int           `env:"ACCRUAL_RATE_LIMIT"`
+	ThrottleRatio   float64       `env:"ACCRUAL_THROTTLE_RATIO"`
+	RetryAfter      time.Duration `env:"ACCRUAL_RETRY_AFTER"`
+	ProgressOnQuery bool          `env:"ACCRUAL_PROGRESS_ON_QUERY"`
+}
+
+type Rule struct {
+	Pattern       *regexp.Regexp
+	Accrual       float64
+	Invalid       bool
+	NotRegistered bool
+}
+
+func ParseStatuses(value string) ([]string, error) {
+	statuses := make([]string, 0)
+
+	for _, status := range strings.Split(value, ",") {
+		status = strings.ToUpper(strings.TrimSpace(status))
+		if status == "" {
+			continue
+		}
+
+		switch status {
+		case StatusRegistered, StatusProcessing, StatusProcessed:
+			statuses = append(statuses, status)
+		default:
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidStatuses, status)
+		}
+	}
+
+	if len(statuses) == 0 || statuses[len(statuses)-1] != StatusProcessed {
+		return nil, ErrInvalidStatuses
+	}
+
+	return statuses, nil
+}
+
+// ParseRules разбирает правила вида "pattern=value;pattern=value", где value —
+// число баллов, INVALID (отказ в расчёте) или NONE (заказ не зарегистрирован).
+func ParseRules(value string) ([]Rule, error) {
+	rules := make([]Rule, 0)
+
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		i := strings.LastIndex(item, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRule, item)
+		}
+
+		pattern, err := regexp.Compile(item[:i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+
+		rule := Rule{Pattern: pattern}
+
+		switch action := strings.TrimSpace(item[i+1:]); strings.ToUpper(action) {
+		case ruleInvalid:
+			rule.Invalid = true
+		case ruleNotRegistered:
+			rule.NotRegistered = true
+		default:
+			rule.Accrual, err = strconv.ParseFloat(action, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidRule, item)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
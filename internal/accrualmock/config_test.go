@@ -0,0 +1,62 @@
+package accrualmock_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/accrualmock"
+)
+
+func TestParseStatuses(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+		err   error
+	}{
+		{
+			name:  "case 1",
+			value: "REGISTERED,PROCESSING,PROCESSED",
+			want:  []string{"REGISTERED", "PROCESSING", "PROCESSED"},
+		},
+		{
+			name:  "case 2",
+			value: " processed ",
+			want:  []string{"PROCESSED"},
+		},
+		{
+			name:  "case 3",
+			value: "REGISTERED,PROCESSING",
+			err:   accrualmock.ErrInvalidStatuses,
+		},
+		{
+			name:  "case 4",
+			value: "NEW,PROCESSED",
+			err:   accrualmock.ErrInvalidStatuses,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses, err := accrualmock.ParseStatuses(tt.value)
+			assert.True(t, errors.Is(err, tt.err))
+			assert.Equal(t, tt.want, statuses)
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := accrualmock.ParseRules("^1=500; 3$=invalid ;^9=NONE")
+	assert.NoError(t, err)
+	assert.Len(t, rules, 3)
+	assert.Equal(t, float64(500), rules[0].Accrual)
+	assert.True(t, rules[1].Invalid)
+	assert.True(t, rules[2].NotRegistered)
+
+	_, err = accrualmock.ParseRules("^1=lots")
+	assert.ErrorIs(t, err, accrualmock.ErrInvalidRule)
+
+	_, err = accrualmock.ParseRules("([=1")
+	assert.ErrorIs(t, err, accrualmock.ErrInvalidRule)
+}
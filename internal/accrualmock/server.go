@@ -0,0 +1,220 @@
+package accrualmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Server struct {
+	statuses        []string
+	step            time.Duration
+	rules           []Rule
+	defaultAccrual  float64
+	latency         time.Duration
+	jitter          time.Duration
+	rateLimit       int
+	throttleRatio   float64
+	retryAfter      time.Duration
+	progressOnQuery bool
+
+	mu          sync.Mutex
+	orders      map[string]*orderState
+	random      *rand.Rand
+	windowStart time.Time
+	windowCount int
+	now         func() time.Time
+}
+
+type orderState struct {
+	firstSeen time.Time
+	queries   int
+}
+
+type orderResponse struct {
+	Order   string   `json:"order"`
+	Status  string   `json:"status"`
+	Accrual *float64 `json:"accrual,omitempty"`
+}
+
+func NewServer(config Config) (*Server, error) {
+	statuses, err := ParseStatuses(config.Statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := ParseRules(config.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	retryAfter := config.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = time.Minute
+	}
+
+	return &Server{
+		statuses:        statuses,
+		step:            config.Step,
+		rules:           rules,
+		defaultAccrual:  config.DefaultAccrual,
+		latency:         config.Latency,
+		jitter:          config.Jitter,
+		rateLimit:       config.RateLimit,
+		throttleRatio:   config.ThrottleRatio,
+		retryAfter:      retryAfter,
+		progressOnQuery: config.ProgressOnQuery,
+		orders:          make(map[string]*orderState),
+		random:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:             time.Now,
+	}, nil
+}
+
+func (s *Server) Handler() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/api/orders/{number}", s.Order)
+
+	return r
+}
+
+func (s *Server) Order(w http.ResponseWriter, r *http.Request) {
+	number := chi.URLParam(r, "number")
+
+	delay, retryAfter, throttled := s.admit()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if throttled {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, "No more than %d requests per minute allowed\n", s.rateLimit)
+
+		return
+	}
+
+	response, ok := s.progress(number)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) admit() (delay, retryAfter time.Duration, throttled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delay = s.latency
+	if s.jitter > 0 {
+		delay += time.Duration(s.random.Int63n(int64(s.jitter)))
+	}
+
+	if s.throttleRatio > 0 && s.random.Float64() < s.throttleRatio {
+		return delay, s.retryAfter, true
+	}
+
+	if s.rateLimit <= 0 {
+		return delay, 0, false
+	}
+
+	now := s.now()
+	if now.Sub(s.windowStart) >= time.Minute {
+		s.windowStart = now
+		s.windowCount = 0
+	}
+
+	s.windowCount++
+	if s.windowCount > s.rateLimit {
+		return delay, s.windowStart.Add(time.Minute).Sub(now).Round(time.Second), true
+	}
+
+	return delay, 0, false
+}
+
+func (s *Server) progress(number string) (response orderResponse, ok bool) {
+	rule, matched := s.match(number)
+	if matched && rule.NotRegistered {
+		return response, false
+	}
+
+	s.mu.Lock()
+	state, exists := s.orders[number]
+	if !exists {
+		state = &orderState{firstSeen: s.now()}
+		s.orders[number] = state
+	}
+	state.queries++
+	stage := s.stage(state)
+	s.mu.Unlock()
+
+	response.Order = number
+	response.Status = s.statuses[stage]
+
+	if response.Status != StatusProcessed {
+		return response, true
+	}
+
+	switch {
+	case matched && rule.Invalid:
+		response.Status = StatusInvalid
+	case matched:
+		accrual := rule.Accrual
+		response.Accrual = &accrual
+	case s.defaultAccrual > 0:
+		accrual := s.defaultAccrual
+		response.Accrual = &accrual
+	}
+
+	return response, true
+}
+
+func (s *Server) stage(state *orderState) int {
+	last := len(s.statuses) - 1
+
+	var stage int
+
+	switch {
+	case s.progressOnQuery:
+		stage = state.queries - 1
+	case s.step > 0:
+		stage = int(s.now().Sub(state.firstSeen) / s.step)
+	default:
+		stage = last
+	}
+
+	if stage > last {
+		return last
+	}
+
+	return stage
+}
+
+func (s *Server) match(number string) (Rule, bool) {
+	for _, rule := range s.rules {
+		if rule.Pattern.MatchString(number) {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
@@ -0,0 +1,101 @@
+package accrualmock_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/accrualmock"
+)
+
+type orderResponse struct {
+	Order   string   `json:"order"`
+	Status  string   `json:"status"`
+	Accrual *float64 `json:"accrual"`
+}
+
+func get(t *testing.T, server *httptest.Server, number string) (*http.Response, orderResponse) {
+	t.Helper()
+
+	var body orderResponse
+
+	resp, err := http.Get(server.URL + "/api/orders/" + number)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	}
+
+	return resp, body
+}
+
+func TestServerProgression(t *testing.T) {
+	mServer, err := accrualmock.NewServer(accrualmock.Config{
+		Statuses:        "REGISTERED,PROCESSING,PROCESSED",
+		Rules:           "^1=500;3$=INVALID;^9=NONE",
+		DefaultAccrual:  100,
+		ProgressOnQuery: true,
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(mServer.Handler())
+	defer server.Close()
+
+	for _, want := range []string{"REGISTERED", "PROCESSING", "PROCESSED", "PROCESSED"} {
+		resp, body := get(t, server, "12345678903")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, want, body.Status)
+	}
+
+	_, body := get(t, server, "12345678903")
+	require.NotNil(t, body.Accrual)
+	assert.Equal(t, float64(500), *body.Accrual)
+
+	get(t, server, "2377225623")
+	get(t, server, "2377225623")
+	_, body = get(t, server, "2377225623")
+	assert.Equal(t, "INVALID", body.Status)
+	assert.Nil(t, body.Accrual)
+
+	get(t, server, "2377225624")
+	get(t, server, "2377225624")
+	_, body = get(t, server, "2377225624")
+	require.NotNil(t, body.Accrual)
+	assert.Equal(t, float64(100), *body.Accrual)
+
+	resp, _ := get(t, server, "9278923470")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestServerRateLimit(t *testing.T) {
+	mServer, err := accrualmock.NewServer(accrualmock.Config{Statuses: "PROCESSED", RateLimit: 2})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(mServer.Handler())
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, _ := get(t, server, "12345678903")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp, _ := get(t, server, "12345678903")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+}
+
+func TestServerThrottle(t *testing.T) {
+	mServer, err := accrualmock.NewServer(accrualmock.Config{Statuses: "PROCESSED", ThrottleRatio: 1})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(mServer.Handler())
+	defer server.Close()
+
+	resp, _ := get(t, server, "12345678903")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/jwtauth"
+)
+
+var (
+	ErrNotFindClientID = errors.New("not find client id")
+	ErrUnauthorized    = errors.New("unauthorized")
+)
+
+func NewToken(tokenAuth *jwtauth.JWTAuth, clientID int) (tokenString string, err error) {
+	claims := map[string]interface{}{"client_id": strconv.Itoa(clientID)}
+	jwtauth.SetExpiry(claims, time.Now().Add(time.Hour))
+
+	_, tokenString, err = tokenAuth.Encode(claims)
+
+	return
+}
+
+// ClientIDFromContext достаёт идентификатор клиента из токена, который
+// jwtauth.Verifier (или gRPC-перехватчик) положил в контекст.
+func ClientIDFromContext(ctx context.Context) (id int, err error) {
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	clientID, ok := claims["client_id"]
+	if !ok {
+		return 0, ErrNotFindClientID
+	}
+
+	value, ok := clientID.(string)
+	if !ok {
+		return 0, ErrNotFindClientID
+	}
+
+	id, err = strconv.Atoi(value)
+	if err != nil {
+		return 0, ErrNotFindClientID
+	}
+
+	return
+}
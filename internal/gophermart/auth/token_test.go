@@ -0,0 +1,55 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/auth"
+)
+
+func TestClientIDFromContext(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+
+	tokenString, err := auth.NewToken(tokenAuth, 42)
+	require.NoError(t, err)
+
+	token, err := jwtauth.VerifyToken(tokenAuth, tokenString)
+	require.NoError(t, err)
+
+	foreign, _, err := tokenAuth.Encode(map[string]interface{}{"client_id": "admin"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		want    int
+		wantErr error
+	}{
+		{
+			name: "case 1",
+			ctx:  jwtauth.NewContext(context.Background(), token, nil),
+			want: 42,
+		},
+		{
+			name:    "case 2",
+			ctx:     context.Background(),
+			wantErr: auth.ErrNotFindClientID,
+		},
+		{
+			name:    "case 3",
+			ctx:     jwtauth.NewContext(context.Background(), foreign, nil),
+			wantErr: auth.ErrNotFindClientID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := auth.ClientIDFromContext(tt.ctx)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+}
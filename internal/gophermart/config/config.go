@@ -1,7 +1,24 @@
 package config
 
+import "time"
+
 type Config struct {
-	RunAddress           string `env:"RUN_ADDRESS"`
-	DataBaseURI          string `env:"DATABASE_URI"`
-	AccrualSystemAddress string `env:"ACCRUAL_SYSTEM_ADDRESS"`
+	RunAddress           string        `env:"RUN_ADDRESS"`
+	GRPCAddress          string        `env:"GRPC_ADDRESS"`
+	DataBaseURI          string        `env:"DATABASE_URI"`
+	AccrualSystemAddress string        `env:"ACCRUAL_SYSTEM_ADDRESS"`
+	AccrualTimeout       time.Duration `env:"ACCRUAL_TIMEOUT"`
+	AccrualWebhookSecret string        `env:"ACCRUAL_WEBHOOK_SECRET"`
+	AdminToken           string        `env:"ADMIN_TOKEN"`
+	OutboxSinks          string        `env:"OUTBOX_SINKS"`
+	OutboxFile           string        `env:"OUTBOX_FILE"`
+	StatementNotifier    string        `env:"STATEMENT_NOTIFIER"`
+
+	DBMaxOpenConns           int           `env:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns           int           `env:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime        time.Duration `env:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime        time.Duration `env:"DB_CONN_MAX_IDLE_TIME"`
+	DBStatementCacheCapacity int           `env:"DB_STATEMENT_CACHE_CAPACITY"`
+	DBConnectAttempts        int           `env:"DB_CONNECT_ATTEMPTS"`
+	DBConnectInterval        time.Duration `env:"DB_CONNECT_INTERVAL"`
 }
@@ -0,0 +1,201 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	TypeOrder   = "order"
+	TypeBalance = "balance"
+	// TypeReset означает, что пропущенные события восстановить не удалось и
+	// клиенту нужно заново запросить состояние.
+	TypeReset = "reset"
+
+	subscriberBuffer = 16
+	replayLimit      = 1000
+)
+
+type Event struct {
+	ID       uint64
+	ClientID int
+	Type     string
+	Data     interface{}
+}
+
+type OrderStatus struct {
+	Number  string  `json:"number"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual,omitempty"`
+}
+
+// Replay возвращает события клиента после afterEventID из постоянного
+// хранилища, например из outbox, не больше limit.
+type Replay func(ctx context.Context, clientID int, afterEventID uint64, limit int) ([]Event, error)
+
+type Bus struct {
+	// Replay восстанавливает события, которых уже нет в истории шины: после
+	// перезапуска или если клиент подключался к другому экземпляру.
+	Replay Replay
+
+	mu          sync.Mutex
+	lastID      uint64
+	started     bool
+	floor       uint64
+	history     []Event
+	historySize int
+	subscribers map[int]map[chan Event]struct{}
+}
+
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		history:     make([]Event, 0, historySize),
+		historySize: historySize,
+		subscribers: make(map[int]map[chan Event]struct{}),
+	}
+}
+
+func (b *Bus) Publish(clientID int, eventType string, data interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.publish(b.lastID+1, clientID, eventType, data)
+}
+
+// PublishID публикует событие с заданным идентификатором, например с номером
+// события outbox, чтобы Last-Event-ID можно было найти и после перезапуска.
+// Идентификаторы должны возрастать.
+func (b *Bus) PublishID(id uint64, clientID int, eventType string, data interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.publish(id, clientID, eventType, data)
+}
+
+func (b *Bus) publish(id uint64, clientID int, eventType string, data interface{}) Event {
+	event := Event{ID: id, ClientID: clientID, Type: eventType, Data: data}
+
+	// История шины полна только начиная с floor: раньше шина не работала
+	// или события вытеснены из буфера.
+	if !b.started {
+		b.started = true
+		b.floor = id - 1
+	}
+
+	b.lastID = id
+
+	if len(b.history) == b.historySize {
+		if b.historySize == 0 {
+			b.floor = id
+		} else {
+			b.floor = b.history[0].ID
+			copy(b.history, b.history[1:])
+			b.history = b.history[:len(b.history)-1]
+		}
+	}
+
+	if b.historySize > 0 {
+		b.history = append(b.history, event)
+	}
+
+	for ch := range b.subscribers[clientID] {
+		select {
+		case ch <- event:
+		default:
+			// Медленный подписчик отключается и переподключится с Last-Event-ID.
+			delete(b.subscribers[clientID], ch)
+			close(ch)
+		}
+	}
+
+	return event
+}
+
+// Subscribe возвращает канал новых событий клиента и события из истории,
+// опубликованные после lastEventID.
+func (b *Bus) Subscribe(clientID int, lastEventID uint64) (events <-chan Event, backlog []Event, unsubscribe func()) {
+	events, backlog, _, _, unsubscribe = b.subscribe(clientID, lastEventID)
+
+	return events, backlog, unsubscribe
+}
+
+// Resume подписывает клиента, который переподключился с Last-Event-ID. Если
+// история шины не покрывает lastEventID, пропущенные события берутся из
+// Replay, а когда и это невозможно, первым в backlog идёт событие TypeReset.
+// Канал может повторить события из backlog, поэтому получатель пропускает
+// идентификаторы не больше последнего отправленного.
+func (b *Bus) Resume(ctx context.Context, clientID int, lastEventID uint64) (events <-chan Event, backlog []Event, unsubscribe func(), err error) {
+	events, backlog, covered, floor, unsubscribe := b.subscribe(clientID, lastEventID)
+	if covered {
+		return events, backlog, unsubscribe, nil
+	}
+
+	var replayed []Event
+
+	if b.Replay != nil {
+		replayed, err = b.Replay(ctx, clientID, lastEventID, replayLimit)
+		if err != nil {
+			unsubscribe()
+
+			return nil, nil, nil, err
+		}
+	}
+
+	if b.Replay == nil || len(replayed) == replayLimit {
+		reset := Event{ID: floor, ClientID: clientID, Type: TypeReset, Data: struct{}{}}
+
+		return events, append([]Event{reset}, backlog...), unsubscribe, nil
+	}
+
+	last := lastEventID
+	if len(replayed) > 0 {
+		last = replayed[len(replayed)-1].ID
+	}
+
+	for _, event := range backlog {
+		if event.ID > last {
+			replayed = append(replayed, event)
+		}
+	}
+
+	return events, replayed, unsubscribe, nil
+}
+
+func (b *Bus) subscribe(clientID int, lastEventID uint64) (events <-chan Event, backlog []Event, covered bool, floor uint64, unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	covered = lastEventID == 0 || (b.started && lastEventID >= b.floor)
+
+	if lastEventID > 0 && lastEventID <= b.lastID {
+		for _, event := range b.history {
+			if event.ID > lastEventID && event.ClientID == clientID {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+
+	if b.subscribers[clientID] == nil {
+		b.subscribers[clientID] = make(map[chan Event]struct{})
+	}
+
+	b.subscribers[clientID][ch] = struct{}{}
+
+	unsubscribe = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscribers[clientID][ch]; ok {
+			delete(b.subscribers[clientID], ch)
+			close(ch)
+		}
+
+		if len(b.subscribers[clientID]) == 0 {
+			delete(b.subscribers, clientID)
+		}
+	}
+
+	return ch, backlog, covered, b.floor, unsubscribe
+}
@@ -0,0 +1,109 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/events"
+)
+
+func TestBus(t *testing.T) {
+	bus := events.NewBus(2)
+
+	stream, backlog, unsubscribe := bus.Subscribe(1, 0)
+	defer unsubscribe()
+
+	assert.Empty(t, backlog)
+
+	first := bus.Publish(1, events.TypeOrder, "first")
+	bus.Publish(2, events.TypeOrder, "another client")
+
+	assert.Equal(t, first, <-stream)
+
+	second := bus.Publish(1, events.TypeBalance, "second")
+	third := bus.Publish(1, events.TypeBalance, "third")
+
+	assert.Equal(t, second, <-stream)
+	assert.Equal(t, third, <-stream)
+
+	_, backlog, unsubscribeResumed := bus.Subscribe(1, second.ID-1)
+	defer unsubscribeResumed()
+
+	assert.Equal(t, []events.Event{second, third}, backlog)
+}
+
+func TestBusSlowSubscriber(t *testing.T) {
+	bus := events.NewBus(0)
+
+	stream, _, unsubscribe := bus.Subscribe(1, 0)
+	defer unsubscribe()
+
+	for i := 0; i < 100; i++ {
+		bus.Publish(1, events.TypeOrder, i)
+	}
+
+	count := 0
+	for range stream {
+		count++
+	}
+
+	assert.Less(t, count, 100)
+}
+
+func TestBusResume(t *testing.T) {
+	ctx := context.Background()
+
+	bus := events.NewBus(2)
+	for id := uint64(11); id <= 14; id++ {
+		bus.PublishID(id, 1, events.TypeOrder, id)
+	}
+
+	// История покрывает Last-Event-ID.
+	_, backlog, unsubscribe, err := bus.Resume(ctx, 1, 12)
+	require.NoError(t, err)
+	unsubscribe()
+
+	assert.Equal(t, []uint64{13, 14}, eventIDs(backlog))
+
+	// Без Replay клиент получает reset.
+	_, backlog, unsubscribe, err = bus.Resume(ctx, 1, 5)
+	require.NoError(t, err)
+	unsubscribe()
+
+	require.NotEmpty(t, backlog)
+	assert.Equal(t, events.TypeReset, backlog[0].Type)
+	assert.Equal(t, []uint64{12, 13, 14}, eventIDs(backlog))
+
+	// Пропущенные события берутся из Replay, повторы из истории отбрасываются.
+	bus.Replay = func(ctx context.Context, clientID int, afterEventID uint64, limit int) ([]events.Event, error) {
+		assert.Equal(t, 1, clientID)
+		assert.Equal(t, uint64(5), afterEventID)
+
+		return []events.Event{{ID: 7, ClientID: 1}, {ID: 13, ClientID: 1}}, nil
+	}
+
+	_, backlog, unsubscribe, err = bus.Resume(ctx, 1, 5)
+	require.NoError(t, err)
+	unsubscribe()
+
+	assert.Equal(t, []uint64{7, 13, 14}, eventIDs(backlog))
+
+	// Шина перезапущена и ещё ничего не публиковала.
+	_, backlog, unsubscribe, err = events.NewBus(2).Resume(ctx, 1, 5)
+	require.NoError(t, err)
+	unsubscribe()
+
+	require.Len(t, backlog, 1)
+	assert.Equal(t, events.TypeReset, backlog[0].Type)
+}
+
+func eventIDs(backlog []events.Event) []uint64 {
+	ids := make([]uint64, 0, len(backlog))
+	for _, event := range backlog {
+		ids = append(ids, event.ID)
+	}
+
+	return ids
+}
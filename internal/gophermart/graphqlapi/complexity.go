@@ -0,0 +1,178 @@
+package graphqlapi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+var (
+	ErrQueryTooDeep    = errors.New("query is too deep")
+	ErrQueryTooComplex = errors.New("query is too complex")
+)
+
+const timelineEstimate = 10
+
+// listSizes — ожидаемое число элементов спискового поля; если у поля есть
+// аргумент limit, используется он.
+var listSizes = map[string]int{
+	"orders":      models.DefaultPageLimit,
+	"withdrawals": models.DefaultPageLimit,
+	"timeline":    timelineEstimate,
+}
+
+func limitCode(err error) (string, bool) {
+	switch {
+	case errors.Is(err, ErrQueryTooDeep):
+		return "query_too_deep", true
+	case errors.Is(err, ErrQueryTooComplex):
+		return "query_too_complex", true
+	}
+
+	return "", false
+}
+
+type analyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	maxDepth  int
+}
+
+// checkLimits оценивает запрос до выполнения: каждое поле стоит единицу,
+// стоимость вложенных полей списка умножается на его ожидаемый размер.
+func checkLimits(document *ast.Document, operationName string, variables map[string]interface{}, maxDepth, maxComplexity int) error {
+	a := analyzer{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		maxDepth:  maxDepth,
+	}
+
+	var operations []*ast.OperationDefinition
+
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operations = append(operations, definition)
+			}
+		}
+	}
+
+	for _, operation := range operations {
+		complexity, err := a.selectionSet(operation.SelectionSet, 1, map[string]bool{})
+		if err != nil {
+			return err
+		}
+
+		if complexity > maxComplexity {
+			return fmt.Errorf("%w: complexity %d exceeds limit %d", ErrQueryTooComplex, complexity, maxComplexity)
+		}
+	}
+
+	return nil
+}
+
+func (a *analyzer) selectionSet(set *ast.SelectionSet, depth int, visited map[string]bool) (complexity int, err error) {
+	if set == nil {
+		return 0, nil
+	}
+
+	for _, selection := range set.Selections {
+		var cost int
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			cost, err = a.field(selection, depth, visited)
+		case *ast.InlineFragment:
+			cost, err = a.selectionSet(selection.SelectionSet, depth, visited)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+
+			fragment, ok := a.fragments[name]
+			if !ok || visited[name] {
+				continue
+			}
+
+			visited[name] = true
+			cost, err = a.selectionSet(fragment.SelectionSet, depth, visited)
+			delete(visited, name)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		complexity += cost
+	}
+
+	return complexity, nil
+}
+
+func (a *analyzer) field(field *ast.Field, depth int, visited map[string]bool) (int, error) {
+	// __typename ничего не загружает; остальная интроспекция оценивается
+	// как обычные поля.
+	if field.Name.Value == "__typename" {
+		return 0, nil
+	}
+
+	if depth > a.maxDepth {
+		return 0, fmt.Errorf("%w: depth exceeds limit %d", ErrQueryTooDeep, a.maxDepth)
+	}
+
+	children, err := a.selectionSet(field.SelectionSet, depth+1, visited)
+	if err != nil {
+		return 0, err
+	}
+
+	if size, ok := listSizes[field.Name.Value]; ok {
+		if limit, ok := a.intArgument(field, "limit"); ok {
+			size = clampListSize(limit)
+		}
+
+		children *= size
+	}
+
+	return 1 + children, nil
+}
+
+// clampListSize ограничивает limit допустимым размером страницы, чтобы
+// отрицательный limit не уменьшал стоимость остальных полей запроса.
+func clampListSize(limit int) int {
+	switch {
+	case limit < 1:
+		return 1
+	case limit > models.MaxPageLimit:
+		return models.MaxPageLimit
+	}
+
+	return limit
+}
+
+func (a *analyzer) intArgument(field *ast.Field, name string) (int, bool) {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != name {
+			continue
+		}
+
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			n, err := strconv.Atoi(value.Value)
+
+			return n, err == nil
+		case *ast.Variable:
+			switch n := a.variables[value.Name.Value].(type) {
+			case float64:
+				return int(n), true
+			case int:
+				return n, true
+			}
+		}
+	}
+
+	return 0, false
+}
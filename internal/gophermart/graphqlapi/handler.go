@@ -0,0 +1,142 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/vukit/gomac/internal/gophermart/auth"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/problems"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+const (
+	defaultMaxDepth      = 8
+	defaultMaxComplexity = 5000
+)
+
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response повторяет graphql.Result, но не отдаёт data, если запрос
+// не дошёл до выполнения.
+type Response struct {
+	Data   interface{}                `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+type Handler struct {
+	Repo          repositories.Repo
+	Logger        *logger.Logger
+	MaxDepth      int
+	MaxComplexity int
+
+	schema graphql.Schema
+}
+
+func NewHandler(repo repositories.Repo, mLogger *logger.Logger) (*Handler, error) {
+	schema, err := newSchema(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		Repo:          repo,
+		Logger:        mLogger,
+		MaxDepth:      defaultMaxDepth,
+		MaxComplexity: defaultMaxComplexity,
+		schema:        schema,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	request := Request{}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		err = fmt.Errorf("%w: %v", problems.ErrMalformedRequest, err)
+		h.write(w, http.StatusBadRequest, &Response{Errors: h.formatErrors(gqlerrors.FormatErrors(err))})
+
+		return
+	}
+
+	h.write(w, http.StatusOK, h.Do(r.Context(), request))
+}
+
+func (h *Handler) Do(ctx context.Context, request Request) *Response {
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(request.Query)})})
+	if err != nil {
+		return &Response{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	if err = checkLimits(document, request.OperationName, request.Variables, h.MaxDepth, h.MaxComplexity); err != nil {
+		return &Response{Errors: h.formatErrors(gqlerrors.FormatErrors(err))}
+	}
+
+	clientID, err := auth.ClientIDFromContext(ctx)
+	if err != nil {
+		return &Response{Errors: h.formatErrors(gqlerrors.FormatErrors(err))}
+	}
+
+	ctx = context.WithValue(ctx, loadersKey{}, newLoaders(h.Repo, models.Client{ID: clientID}))
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        ctx,
+	})
+
+	return &Response{Data: result.Data, Errors: h.formatErrors(result.Errors)}
+}
+
+// formatErrors дополняет ошибки резолверов кодом из общей таблицы
+// problem+json и скрывает текст внутренних ошибок.
+func (h *Handler) formatErrors(errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	for i, formatted := range errs {
+		original := formatted.OriginalError()
+		if located, ok := original.(*gqlerrors.Error); ok {
+			original = located.OriginalError
+		}
+
+		if original == nil {
+			continue
+		}
+
+		if code, ok := limitCode(original); ok {
+			errs[i].Extensions = map[string]interface{}{"code": code}
+
+			continue
+		}
+
+		problem := problems.New(original)
+
+		if problem.Status >= http.StatusInternalServerError {
+			h.Logger.Warning(fmt.Sprintf("graphql: %s", original))
+
+			errs[i].Message = problem.Title
+		}
+
+		errs[i].Extensions = map[string]interface{}{"code": problem.Code}
+	}
+
+	return errs
+}
+
+func (h *Handler) write(w http.ResponseWriter, status int, response *Response) {
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,347 @@
+package graphqlapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/graphqlapi"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+type fakeRepo struct {
+	repositories.Repo
+	orders      []models.Order
+	withdrawals []models.Withdrawal
+	events      map[int][]models.OrderEvent
+	balanceErr  error
+
+	numbersCalls [][]string
+	eventsCalls  [][]int
+}
+
+func (r *fakeRepo) FindOrdersPage(ctx context.Context, client models.Client, query models.ListQuery) (models.OrdersPage, error) {
+	page := models.OrdersPage{}
+
+	for _, order := range r.orders {
+		if order.ClientID == client.ID && len(page.Orders) < query.Limit {
+			page.Orders = append(page.Orders, order)
+		}
+	}
+
+	return page, nil
+}
+
+func (r *fakeRepo) FindOrdersByNumbers(ctx context.Context, client models.Client, numbers []string) ([]models.Order, error) {
+	r.numbersCalls = append(r.numbersCalls, numbers)
+
+	orders := []models.Order{}
+
+	for _, order := range r.orders {
+		for _, number := range numbers {
+			if order.ClientID == client.ID && order.Number == number {
+				orders = append(orders, order)
+			}
+		}
+	}
+
+	return orders, nil
+}
+
+func (r *fakeRepo) FindOrdersEvents(ctx context.Context, orderIDs []int) (map[int][]models.OrderEvent, error) {
+	r.eventsCalls = append(r.eventsCalls, orderIDs)
+
+	events := map[int][]models.OrderEvent{}
+	for _, id := range orderIDs {
+		events[id] = r.events[id]
+	}
+
+	return events, nil
+}
+
+func (r *fakeRepo) FindWithdrawalsPage(ctx context.Context, client models.Client, query models.ListQuery) (models.WithdrawalsPage, error) {
+	return models.WithdrawalsPage{Withdrawals: r.withdrawals}, nil
+}
+
+func (r *fakeRepo) FindBalance(ctx context.Context, client models.Client) (*models.Balace, error) {
+	if r.balanceErr != nil {
+		return nil, r.balanceErr
+	}
+
+	return &models.Balace{Current: 500, Withdrawn: 42}, nil
+}
+
+func newRepo() *fakeRepo {
+	return &fakeRepo{
+		orders: []models.Order{
+			{ID: 1, ClientID: 1, Number: "12345678903", Status: "PROCESSED", Accrual: 500, UploadedAt: "2020-12-10T15:15:45+03:00"},
+			{ID: 2, ClientID: 1, Number: "9278923470", Status: "NEW", UploadedAt: "2020-12-10T15:12:01+03:00"},
+			{ID: 3, ClientID: 2, Number: "346436439", Status: "NEW", UploadedAt: "2020-12-09T16:09:53+03:00"},
+		},
+		withdrawals: []models.Withdrawal{
+			{ClientID: 1, Order: "2377225624", Sum: 42, ProcessedAt: "2020-12-11T10:00:00+03:00"},
+		},
+		events: map[int][]models.OrderEvent{
+			1: {
+				{Event: models.OrderEventUploaded, Status: "NEW", CreatedAt: "2020-12-10T15:15:45+03:00"},
+				{Event: models.OrderEventFinal, StatusFrom: "NEW", Status: "PROCESSED", Accrual: 500, CreatedAt: "2020-12-10T15:16:45+03:00"},
+			},
+		},
+	}
+}
+
+func clientContext(t *testing.T, clientID string) context.Context {
+	t.Helper()
+
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+
+	token, _, err := tokenAuth.Encode(map[string]interface{}{"client_id": clientID})
+	require.NoError(t, err)
+
+	return jwtauth.NewContext(context.Background(), token, nil)
+}
+
+func do(t *testing.T, h *graphqlapi.Handler, ctx context.Context, query string, variables map[string]interface{}) (data map[string]interface{}, errs []map[string]interface{}) {
+	t.Helper()
+
+	response := h.Do(ctx, graphqlapi.Request{Query: query, Variables: variables})
+
+	body, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	var decoded struct {
+		Data   map[string]interface{}   `json:"data"`
+		Errors []map[string]interface{} `json:"errors"`
+	}
+
+	require.NoError(t, json.Unmarshal(body, &decoded))
+
+	return decoded.Data, decoded.Errors
+}
+
+func errorCode(errs []map[string]interface{}) string {
+	if len(errs) == 0 {
+		return ""
+	}
+
+	extensions, _ := errs[0]["extensions"].(map[string]interface{})
+	code, _ := extensions["code"].(string)
+
+	return code
+}
+
+func TestDashboard(t *testing.T) {
+	repo := newRepo()
+
+	h, err := graphqlapi.NewHandler(repo, logger.NewLogger(io.Discard))
+	require.NoError(t, err)
+
+	data, errs := do(t, h, clientContext(t, "1"), `{
+		me {
+			id
+			balance { current withdrawn }
+			orders { number status accrual uploadedAt timeline { event statusFrom status } }
+			withdrawals { order sum processedAt }
+		}
+	}`, nil)
+	require.Empty(t, errs)
+
+	me := data["me"].(map[string]interface{})
+	assert.Equal(t, float64(1), me["id"])
+	assert.Equal(t, map[string]interface{}{"current": float64(500), "withdrawn": float64(42)}, me["balance"])
+
+	orders := me["orders"].([]interface{})
+	require.Len(t, orders, 2)
+	assert.Len(t, orders[0].(map[string]interface{})["timeline"], 2)
+	assert.Equal(t, []interface{}{}, orders[1].(map[string]interface{})["timeline"])
+
+	first := orders[0].(map[string]interface{})["timeline"].([]interface{})
+	assert.Nil(t, first[0].(map[string]interface{})["statusFrom"])
+	assert.Equal(t, "NEW", first[1].(map[string]interface{})["statusFrom"])
+
+	assert.Len(t, me["withdrawals"], 1)
+
+	// История всех заказов загружается одним запросом.
+	require.Len(t, repo.eventsCalls, 1)
+	assert.ElementsMatch(t, []int{1, 2}, repo.eventsCalls[0])
+}
+
+func TestOrderBatching(t *testing.T) {
+	repo := newRepo()
+
+	h, err := graphqlapi.NewHandler(repo, logger.NewLogger(io.Discard))
+	require.NoError(t, err)
+
+	data, errs := do(t, h, clientContext(t, "1"), `{
+		me {
+			a: order(number: "12345678903") { number timeline { event } }
+			b: order(number: "9278923470") { number }
+			c: order(number: "346436439") { number }
+		}
+	}`, nil)
+	require.Empty(t, errs)
+
+	me := data["me"].(map[string]interface{})
+	assert.Equal(t, "12345678903", me["a"].(map[string]interface{})["number"])
+	assert.Equal(t, "9278923470", me["b"].(map[string]interface{})["number"])
+	assert.Nil(t, me["c"])
+
+	require.Len(t, repo.numbersCalls, 1)
+	assert.ElementsMatch(t, []string{"12345678903", "9278923470", "346436439"}, repo.numbersCalls[0])
+	assert.Len(t, repo.eventsCalls, 1)
+}
+
+func TestErrors(t *testing.T) {
+	// Отрицательный limit не должен компенсировать стоимость остальных полей.
+	aliases := strings.Builder{}
+	aliases.WriteString(`{ me { cheap: orders(limit: -100000000) { number } `)
+
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&aliases, `o%d: orders(limit: 1000) { timeline { status } } `, i)
+	}
+
+	aliases.WriteString(`} }`)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		query      string
+		variables  map[string]interface{}
+		balanceErr error
+		wantCode   string
+		wantMsg    string
+	}{
+		{
+			name:     "case 1",
+			ctx:      context.Background(),
+			query:    `{ me { id } }`,
+			wantCode: "unauthorized",
+		},
+		{
+			name:     "case 2",
+			ctx:      clientContext(t, "1"),
+			query:    `{ me { orders(limit: 1000) { timeline { event status statusFrom accrual createdAt } } } }`,
+			wantCode: "query_too_complex",
+		},
+		{
+			name:      "case 3",
+			ctx:       clientContext(t, "1"),
+			query:     `query($n: Int) { me { orders(limit: $n) { timeline { event status statusFrom accrual createdAt } } } }`,
+			variables: map[string]interface{}{"n": float64(1000)},
+			wantCode:  "query_too_complex",
+		},
+		{
+			name:     "case 4",
+			ctx:      clientContext(t, "1"),
+			query:    `{ me { orders(limit: 0) { number } } }`,
+			wantCode: "invalid_page_limit",
+		},
+		{
+			name:     "case 5",
+			ctx:      clientContext(t, "1"),
+			query:    `{ me { balance(at: "yesterday") { current } } }`,
+			wantCode: "invalid_balance_time",
+		},
+		{
+			name:       "case 6",
+			ctx:        clientContext(t, "1"),
+			query:      `{ me { balance { current } } }`,
+			balanceErr: errors.New("connection reset by peer"),
+			wantCode:   "internal_error",
+			wantMsg:    "Internal Server Error",
+		},
+		{
+			name:  "case 7",
+			ctx:   clientContext(t, "1"),
+			query: `{ me { unknown } }`,
+		},
+		{
+			name:     "case 8",
+			ctx:      clientContext(t, "1"),
+			query:    aliases.String(),
+			wantCode: "query_too_complex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo()
+			repo.balanceErr = tt.balanceErr
+
+			h, err := graphqlapi.NewHandler(repo, logger.NewLogger(io.Discard))
+			require.NoError(t, err)
+
+			_, errs := do(t, h, tt.ctx, tt.query, tt.variables)
+			require.NotEmpty(t, errs)
+			assert.Equal(t, tt.wantCode, errorCode(errs))
+
+			if tt.wantMsg != "" {
+				assert.Equal(t, tt.wantMsg, errs[0]["message"])
+			}
+		})
+	}
+}
+
+func TestDepthLimit(t *testing.T) {
+	h, err := graphqlapi.NewHandler(newRepo(), logger.NewLogger(io.Discard))
+	require.NoError(t, err)
+
+	h.MaxDepth = 3
+
+	_, errs := do(t, h, clientContext(t, "1"), `{ me { orders { timeline { event } } } }`, nil)
+	assert.Equal(t, "query_too_deep", errorCode(errs))
+
+	_, errs = do(t, h, clientContext(t, "1"), `fragment o on Order { number } { me { orders { ...o } } }`, nil)
+	assert.Empty(t, errs)
+
+	_, errs = do(t, h, clientContext(t, "1"), `{ __schema { types { fields { type { ofType { name } } } } } }`, nil)
+	assert.Equal(t, "query_too_deep", errorCode(errs))
+
+	_, errs = do(t, h, clientContext(t, "1"), `{ me { __typename orders { __typename } } }`, nil)
+	assert.Empty(t, errs)
+}
+
+func TestServeHTTP(t *testing.T) {
+	h, err := graphqlapi.NewHandler(newRepo(), logger.NewLogger(io.Discard))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{
+			name: "case 1",
+			body: `{"query":"{ me { id } }"}`,
+			want: http.StatusOK,
+		},
+		{
+			name: "case 2",
+			body: `{`,
+			want: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(tt.body)).WithContext(clientContext(t, "1"))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package graphqlapi
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader откладывает загрузку по ключу до первого обращения к результату.
+// Исполнитель graphql-go сначала вызывает резолверы всего уровня и только
+// потом раскрывает отложенные значения, поэтому все ключи уровня попадают
+// в один запрос к репозиторию.
+type Loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending map[K]struct{}
+	results map[K]V
+	errs    map[K]error
+}
+
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		pending: make(map[K]struct{}),
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		l.pending[key] = struct{}{}
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, ok := l.pending[key]; ok {
+			l.dispatch(ctx)
+		}
+
+		return l.results[key], l.errs[key]
+	}
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := make([]K, 0, len(l.pending))
+	for key := range l.pending {
+		keys = append(keys, key)
+	}
+
+	l.pending = make(map[K]struct{})
+
+	results, err := l.fetch(ctx, keys)
+
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+
+			continue
+		}
+
+		l.results[key] = results[key]
+	}
+}
@@ -0,0 +1,250 @@
+package graphqlapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vukit/gomac/internal/gophermart/auth"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
+)
+
+type loadersKey struct{}
+
+// loaders создаются на каждый запрос, чтобы кэш не переживал его.
+type loaders struct {
+	orders *Loader[string, *models.Order]
+	events *Loader[int, []models.OrderEvent]
+}
+
+func newLoaders(repo repositories.Repo, client models.Client) *loaders {
+	return &loaders{
+		orders: NewLoader(func(ctx context.Context, numbers []string) (map[string]*models.Order, error) {
+			orders, err := repo.FindOrdersByNumbers(ctx, client, numbers)
+			if err != nil {
+				return nil, err
+			}
+
+			results := make(map[string]*models.Order, len(orders))
+			for i := range orders {
+				results[orders[i].Number] = &orders[i]
+			}
+
+			return results, nil
+		}),
+		events: NewLoader(func(ctx context.Context, orderIDs []int) (map[int][]models.OrderEvent, error) {
+			return repo.FindOrdersEvents(ctx, orderIDs)
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+
+	return l
+}
+
+var balanceType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Balance",
+	Fields: graphql.Fields{
+		"current":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"withdrawn": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+	},
+})
+
+var orderEventType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "OrderEvent",
+	Fields: graphql.Fields{
+		"event":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"statusFrom": &graphql.Field{Type: graphql.String, Resolve: resolveStatusFrom},
+		"status":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"accrual":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"createdAt":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var orderType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Order",
+	Fields: graphql.Fields{
+		"number":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"status":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"accrual":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"uploadedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"timeline": &graphql.Field{
+			Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(orderEventType))),
+			Resolve: resolveTimeline,
+		},
+	},
+})
+
+var withdrawalType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Withdrawal",
+	Fields: graphql.Fields{
+		"order":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"sum":         &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"processedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+func newSchema(repo repositories.Repo) (graphql.Schema, error) {
+	r := resolver{
+		orderService:   services.NewOrderService(repo),
+		balanceService: services.NewBalanceService(repo),
+	}
+
+	limitArg := &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: models.DefaultPageLimit}
+
+	clientType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Client",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"balance": &graphql.Field{
+				Type:        graphql.NewNonNull(balanceType),
+				Description: "Баланс сейчас или на момент at (RFC3339).",
+				Args:        graphql.FieldConfigArgument{"at": &graphql.ArgumentConfig{Type: graphql.String}},
+				Resolve:     r.balance,
+			},
+			"orders": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(orderType))),
+				Args: graphql.FieldConfigArgument{
+					"limit":  limitArg,
+					"status": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+				},
+				Resolve: r.orders,
+			},
+			"order": &graphql.Field{
+				Type:    orderType,
+				Args:    graphql.FieldConfigArgument{"number": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: resolveOrder,
+			},
+			"withdrawals": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(withdrawalType))),
+				Args:    graphql.FieldConfigArgument{"limit": limitArg},
+				Resolve: r.withdrawals,
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type:    graphql.NewNonNull(clientType),
+				Resolve: resolveMe,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+type resolver struct {
+	orderService   *services.OrderService
+	balanceService *services.BalanceService
+}
+
+func resolveMe(p graphql.ResolveParams) (interface{}, error) {
+	clientID, err := auth.ClientIDFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.Client{ID: clientID}, nil
+}
+
+func (r resolver) balance(p graphql.ResolveParams) (interface{}, error) {
+	client, _ := p.Source.(models.Client)
+
+	var at time.Time
+
+	if value, ok := p.Args["at"].(string); ok {
+		var err error
+
+		if at, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, models.ErrInvalidBalanceTime
+		}
+	}
+
+	return r.balanceService.Balance(p.Context, client, at)
+}
+
+func (r resolver) orders(p graphql.ResolveParams) (interface{}, error) {
+	client, _ := p.Source.(models.Client)
+
+	query := models.ListQuery{Limit: p.Args["limit"].(int)}
+
+	if statuses, ok := p.Args["status"].([]interface{}); ok {
+		for _, status := range statuses {
+			query.Statuses = append(query.Statuses, models.OrderStatus(status.(string)))
+		}
+	}
+
+	page, err := r.orderService.OrdersPage(p.Context, client, query)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*models.Order, 0, len(page.Orders))
+	for i := range page.Orders {
+		orders = append(orders, &page.Orders[i])
+	}
+
+	return orders, nil
+}
+
+func (r resolver) withdrawals(p graphql.ResolveParams) (interface{}, error) {
+	client, _ := p.Source.(models.Client)
+
+	query := models.ListQuery{Limit: p.Args["limit"].(int)}
+
+	page, err := r.balanceService.WithdrawalsPage(p.Context, client, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Withdrawals, nil
+}
+
+func resolveOrder(p graphql.ResolveParams) (interface{}, error) {
+	thunk := loadersFrom(p.Context).orders.Load(p.Context, p.Args["number"].(string))
+
+	return func() (interface{}, error) {
+		order, err := thunk()
+		if err != nil || order == nil {
+			return nil, err
+		}
+
+		return order, nil
+	}, nil
+}
+
+func resolveTimeline(p graphql.ResolveParams) (interface{}, error) {
+	order, _ := p.Source.(*models.Order)
+
+	thunk := loadersFrom(p.Context).events.Load(p.Context, order.ID)
+
+	return func() (interface{}, error) {
+		events, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+
+		if events == nil {
+			events = []models.OrderEvent{}
+		}
+
+		return events, nil
+	}, nil
+}
+
+// Первое событие истории не имеет исходного статуса.
+func resolveStatusFrom(p graphql.ResolveParams) (interface{}, error) {
+	event, _ := p.Source.(models.OrderEvent)
+	if event.StatusFrom == "" {
+		return nil, nil
+	}
+
+	return event.StatusFrom, nil
+}
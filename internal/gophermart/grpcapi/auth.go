@@ -0,0 +1,49 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-chi/jwtauth"
+	"github.com/vukit/gomac/internal/gophermart/auth"
+	"github.com/vukit/gomac/internal/gophermart/grpcapi/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const AuthorizationMetadata = "authorization"
+
+var publicMethods = map[string]bool{
+	pb.GophermartService_Register_FullMethodName: true,
+	pb.GophermartService_Login_FullMethodName:    true,
+}
+
+// authInterceptor проверяет токен из метаданных authorization и кладёт его
+// в контекст так же, как jwtauth.Verifier делает это для HTTP.
+func (s *Server) authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] || !strings.HasPrefix(info.FullMethod, "/"+pb.GophermartService_ServiceDesc.ServiceName+"/") {
+		return handler(ctx, req)
+	}
+
+	token, err := jwtauth.VerifyToken(s.tokenAuth, tokenFromMetadata(ctx))
+	if err != nil {
+		return nil, auth.ErrUnauthorized
+	}
+
+	return handler(jwtauth.NewContext(ctx, token, nil), req)
+}
+
+func tokenFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, value := range md.Get(AuthorizationMetadata) {
+		if len(value) > 7 && strings.EqualFold(value[:7], "Bearer ") {
+			return value[7:]
+		}
+	}
+
+	return ""
+}
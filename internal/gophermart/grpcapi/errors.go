@@ -0,0 +1,53 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/vukit/gomac/internal/gophermart/problems"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Ошибки сопоставляются с кодами через ту же таблицу, что и problem+json
+// в HTTP API, поэтому оба протокола классифицируют их одинаково.
+var problemCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusPaymentRequired:     codes.FailedPrecondition,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+}
+
+func Status(err error) *status.Status {
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+
+	problem := problems.New(err)
+
+	code, ok := problemCodes[problem.Status]
+	if !ok {
+		return status.New(codes.Internal, problem.Title)
+	}
+
+	return status.New(code, problem.Code+": "+problem.Detail)
+}
+
+func (s *Server) errorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+
+	st := Status(err)
+	if st.Code() == codes.Internal {
+		s.mLogger.Warning(fmt.Sprintf("%s: %s", info.FullMethod, err))
+	}
+
+	return nil, st.Err()
+}
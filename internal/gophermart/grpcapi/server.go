@@ -0,0 +1,174 @@
+package grpcapi
+
+//go:generate sh -c "cd ../../.. && buf generate api/proto"
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-chi/jwtauth"
+	"github.com/vukit/gomac/internal/gophermart/auth"
+	"github.com/vukit/gomac/internal/gophermart/grpcapi/pb"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type Server struct {
+	pb.UnimplementedGophermartServiceServer
+
+	tokenAuth *jwtauth.JWTAuth
+	accounts  *services.AccountService
+	orders    *services.OrderService
+	balances  *services.BalanceService
+	mLogger   *logger.Logger
+}
+
+// NewServer собирает gRPC-сервер с сервисом Gophermart и стандартным
+// сервисом проверки здоровья.
+func NewServer(tokenAuth *jwtauth.JWTAuth, repo repositories.Repo, mLogger *logger.Logger) *grpc.Server {
+	s := &Server{
+		tokenAuth: tokenAuth,
+		accounts:  services.NewAccountService(repo),
+		orders:    services.NewOrderService(repo),
+		balances:  services.NewBalanceService(repo),
+		mLogger:   mLogger,
+	}
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		s.errorInterceptor,
+		s.authInterceptor,
+	))
+
+	pb.RegisterGophermartServiceServer(server, s)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.GophermartService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+
+	return server
+}
+
+func (s *Server) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	clientID, err := s.accounts.Register(ctx, models.Client{Login: in.GetLogin(), Password: in.GetPassword()})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := auth.NewToken(s.tokenAuth, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RegisterResponse{Token: token}, nil
+}
+
+func (s *Server) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
+	clientID, err := s.accounts.Login(ctx, models.Client{Login: in.GetLogin(), Password: in.GetPassword()})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := auth.NewToken(s.tokenAuth, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.LoginResponse{Token: token}, nil
+}
+
+func (s *Server) UploadOrder(ctx context.Context, in *pb.UploadOrderRequest) (*pb.UploadOrderResponse, error) {
+	clientID, err := auth.ClientIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.orders.Upload(ctx, &models.Order{ClientID: clientID, Number: in.GetNumber()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UploadOrderResponse{AlreadyUploaded: !created}, nil
+}
+
+func (s *Server) ListOrders(ctx context.Context, in *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	clientID, err := auth.ClientIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.orders.Orders(ctx, models.Client{ID: clientID})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &pb.ListOrdersResponse{Orders: make([]*pb.Order, 0, len(orders))}
+
+	for _, order := range orders {
+		out.Orders = append(out.Orders, &pb.Order{
+			Number:     order.Number,
+			Status:     string(order.Status),
+			Accrual:    order.Accrual,
+			UploadedAt: order.UploadedAt,
+		})
+	}
+
+	return out, nil
+}
+
+func (s *Server) GetBalance(ctx context.Context, in *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	clientID, err := auth.ClientIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.balances.Balance(ctx, models.Client{ID: clientID}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetBalanceResponse{Current: balance.Current, Withdrawn: balance.Withdrawn}, nil
+}
+
+func (s *Server) Withdraw(ctx context.Context, in *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	clientID, err := auth.ClientIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawal := models.Withdrawal{ClientID: clientID, Order: in.GetOrder(), Sum: in.GetSum()}
+
+	if _, err = s.balances.Withdraw(ctx, withdrawal); err != nil {
+		return nil, err
+	}
+
+	return &pb.WithdrawResponse{}, nil
+}
+
+func (s *Server) ListWithdrawals(ctx context.Context, in *pb.ListWithdrawalsRequest) (*pb.ListWithdrawalsResponse, error) {
+	clientID, err := auth.ClientIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawals, err := s.balances.Withdrawals(ctx, models.Client{ID: clientID})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &pb.ListWithdrawalsResponse{Withdrawals: make([]*pb.Withdrawal, 0, len(withdrawals))}
+
+	for _, withdrawal := range withdrawals {
+		out.Withdrawals = append(out.Withdrawals, &pb.Withdrawal{
+			Order:       withdrawal.Order,
+			Sum:         withdrawal.Sum,
+			ProcessedAt: withdrawal.ProcessedAt,
+		})
+	}
+
+	return out, nil
+}
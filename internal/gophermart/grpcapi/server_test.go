@@ -0,0 +1,285 @@
+package grpcapi_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/grpcapi"
+	"github.com/vukit/gomac/internal/gophermart/grpcapi/pb"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/router"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeRepo сам служит транзакцией: InTx передаёт его в fn.
+type fakeRepo struct {
+	repositories.Repo
+	repositories.Tx
+	clients     map[string]models.Client
+	orders      []models.Order
+	withdrawals []models.Withdrawal
+}
+
+func (r *fakeRepo) SaveClient(ctx context.Context, client models.Client) (int, error) {
+	if _, ok := r.clients[client.Login]; ok {
+		return 0, repositories.ErrLoginIsAlreadyTaken
+	}
+
+	client.ID = len(r.clients) + 1
+	r.clients[client.Login] = client
+
+	return client.ID, nil
+}
+
+func (r *fakeRepo) FindClient(ctx context.Context, client models.Client) (int, error) {
+	saved, ok := r.clients[client.Login]
+	if !ok || saved.Password != client.Password {
+		return 0, repositories.ErrInvalidLoginPasswordPair
+	}
+
+	return saved.ID, nil
+}
+
+func (r *fakeRepo) InTx(ctx context.Context, fn func(repositories.Tx) error) error {
+	return fn(r)
+}
+
+func (r *fakeRepo) SaveOrderEvent(ctx context.Context, orderID int, event models.OrderEvent) error {
+	return nil
+}
+
+func (r *fakeRepo) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) error {
+	return nil
+}
+
+func (r *fakeRepo) InsertOrder(ctx context.Context, order *models.Order) error {
+	for _, saved := range r.orders {
+		if saved.Number != order.Number {
+			continue
+		}
+
+		if saved.ClientID == order.ClientID {
+			return repositories.ErrOrderNumberUploadedThisClient
+		}
+
+		return repositories.ErrOrderNumberUploadedAnotherClient
+	}
+
+	order.Status = "NEW"
+	order.UploadedAt = "2023-01-02T03:04:05Z"
+	r.orders = append(r.orders, *order)
+
+	return nil
+}
+
+func (r *fakeRepo) FindOrders(ctx context.Context, client models.Client) ([]models.Order, error) {
+	orders := []models.Order{}
+
+	for _, order := range r.orders {
+		if order.ClientID == client.ID {
+			orders = append(orders, order)
+		}
+	}
+
+	return orders, nil
+}
+
+func (r *fakeRepo) balance(clientID int) *models.Balace {
+	balance := &models.Balace{}
+
+	for _, order := range r.orders {
+		if order.ClientID == clientID {
+			balance.Current += order.Accrual
+		}
+	}
+
+	for _, withdrawal := range r.withdrawals {
+		if withdrawal.ClientID == clientID {
+			balance.Current -= withdrawal.Sum
+			balance.Withdrawn += withdrawal.Sum
+		}
+	}
+
+	return balance
+}
+
+func (r *fakeRepo) FindBalance(ctx context.Context, client models.Client) (*models.Balace, error) {
+	return r.balance(client.ID), nil
+}
+
+func (r *fakeRepo) FindBalanceForUpdate(ctx context.Context, client models.Client) (*models.Balace, error) {
+	return r.balance(client.ID), nil
+}
+
+func (r *fakeRepo) InsertWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	withdrawal.ProcessedAt = "2023-01-02T03:04:05Z"
+	r.withdrawals = append(r.withdrawals, *withdrawal)
+
+	return nil
+}
+
+func (r *fakeRepo) FindWithdrawals(ctx context.Context, client models.Client) ([]models.Withdrawal, error) {
+	withdrawals := []models.Withdrawal{}
+
+	for _, withdrawal := range r.withdrawals {
+		if withdrawal.ClientID == client.ID {
+			withdrawals = append(withdrawals, withdrawal)
+		}
+	}
+
+	return withdrawals, nil
+}
+
+func newConn(t *testing.T, repo *fakeRepo) *grpc.ClientConn {
+	t.Helper()
+
+	tokenAuth, err := router.NewTokenAuth()
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpcapi.NewServer(tokenAuth, repo, logger.NewLogger(io.Discard))
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), grpcapi.AuthorizationMetadata, "Bearer "+token)
+}
+
+func TestServer(t *testing.T) {
+	repo := &fakeRepo{clients: map[string]models.Client{}}
+	client := pb.NewGophermartServiceClient(newConn(t, repo))
+
+	registered, err := client.Register(context.Background(), &pb.RegisterRequest{Login: "user", Password: "secret"})
+	require.NoError(t, err)
+	require.NotEmpty(t, registered.GetToken())
+
+	_, err = client.Register(context.Background(), &pb.RegisterRequest{Login: "user", Password: "secret"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = client.Login(context.Background(), &pb.LoginRequest{Login: "user", Password: "wrong"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	loggedIn, err := client.Login(context.Background(), &pb.LoginRequest{Login: "user", Password: "secret"})
+	require.NoError(t, err)
+
+	ctx := withToken(loggedIn.GetToken())
+
+	uploaded, err := client.UploadOrder(ctx, &pb.UploadOrderRequest{Number: "12345678903"})
+	require.NoError(t, err)
+	assert.False(t, uploaded.GetAlreadyUploaded())
+
+	uploaded, err = client.UploadOrder(ctx, &pb.UploadOrderRequest{Number: "12345678903"})
+	require.NoError(t, err)
+	assert.True(t, uploaded.GetAlreadyUploaded())
+
+	_, err = client.UploadOrder(ctx, &pb.UploadOrderRequest{Number: "12345678900"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	repo.orders[0].Accrual = 500
+
+	orders, err := client.ListOrders(ctx, &pb.ListOrdersRequest{})
+	require.NoError(t, err)
+	require.Len(t, orders.GetOrders(), 1)
+	assert.Equal(t, "12345678903", orders.GetOrders()[0].GetNumber())
+	assert.Equal(t, float64(500), orders.GetOrders()[0].GetAccrual())
+
+	_, err = client.Withdraw(ctx, &pb.WithdrawRequest{Order: "2377225624", Sum: 1000})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.Withdraw(ctx, &pb.WithdrawRequest{Order: "2377225624", Sum: 200})
+	require.NoError(t, err)
+
+	balance, err := client.GetBalance(ctx, &pb.GetBalanceRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, float64(300), balance.GetCurrent())
+	assert.Equal(t, float64(200), balance.GetWithdrawn())
+
+	withdrawals, err := client.ListWithdrawals(ctx, &pb.ListWithdrawalsRequest{})
+	require.NoError(t, err)
+	require.Len(t, withdrawals.GetWithdrawals(), 1)
+	assert.Equal(t, "2377225624", withdrawals.GetWithdrawals()[0].GetOrder())
+}
+
+func TestAuth(t *testing.T) {
+	client := pb.NewGophermartServiceClient(newConn(t, &fakeRepo{clients: map[string]models.Client{}}))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{
+			name: "case 1",
+			ctx:  context.Background(),
+		},
+		{
+			name: "case 2",
+			ctx:  withToken("invalid"),
+		},
+		{
+			name: "case 3",
+			ctx:  metadata.AppendToOutgoingContext(context.Background(), grpcapi.AuthorizationMetadata, "invalid"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetBalance(tt.ctx, &pb.GetBalanceRequest{})
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+}
+
+func TestHealth(t *testing.T) {
+	client := grpc_health_v1.NewHealthClient(newConn(t, &fakeRepo{}))
+
+	tests := []struct {
+		name    string
+		service string
+	}{
+		{
+			name:    "case 1",
+			service: "",
+		},
+		{
+			name:    "case 2",
+			service: pb.GophermartService_ServiceDesc.ServiceName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: tt.service})
+			require.NoError(t, err)
+			assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.GetStatus())
+		})
+	}
+}
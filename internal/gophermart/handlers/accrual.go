@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/vukit/gomac/internal/gophermart/utils"
+)
+
+const (
+	AccrualSignatureHeader = "X-Accrual-Signature"
+
+	maxAccrualBodySize = 1 << 20
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+func (h *Handler) AccrualWebhook(ctx context.Context, secret []byte) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAccrualBodySize))
+		if err != nil {
+			h.writeError(w, r, malformed(err))
+
+			return
+		}
+
+		if !utils.ValidSignature(secret, data, r.Header.Get(AccrualSignatureHeader)) {
+			h.writeError(w, r, ErrInvalidSignature)
+
+			return
+		}
+
+		var update struct {
+			Order   string  `json:"order"`
+			Status  string  `json:"status"`
+			Accrual float64 `json:"accrual"`
+		}
+
+		if err = json.Unmarshal(data, &update); err != nil {
+			h.writeError(w, r, malformed(err))
+
+			return
+		}
+
+		if err = h.orders.ApplyAccrual(ctx, update.Order, update.Status, update.Accrual); err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		fmt.Fprintf(w, "{}")
+	}
+}
@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/utils"
+)
+
+func TestAccrualWebhook(t *testing.T) {
+	secret := []byte("secret")
+
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		want      int
+		saved     int
+	}{
+		{
+			name:      "case 1",
+			body:      `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			signature: utils.Sign(secret, []byte(`{"order":"12345678903","status":"PROCESSED","accrual":500}`)),
+			want:      http.StatusOK,
+			saved:     1,
+		},
+		{
+			name:      "case 2",
+			body:      `{"order":"12345678903","status":"PROCESSING"}`,
+			signature: utils.Sign(secret, []byte(`{"order":"12345678903","status":"PROCESSING"}`)),
+			want:      http.StatusOK,
+			saved:     1,
+		},
+		{
+			name:      "case 3",
+			body:      `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			signature: utils.Sign([]byte("wrong"), []byte(`{"order":"12345678903","status":"PROCESSED","accrual":500}`)),
+			want:      http.StatusUnauthorized,
+			saved:     0,
+		},
+		{
+			name:      "case 4",
+			body:      `{"order":"2377225624","status":"PROCESSED","accrual":500}`,
+			signature: utils.Sign(secret, []byte(`{"order":"2377225624","status":"PROCESSED","accrual":500}`)),
+			want:      http.StatusNotFound,
+			saved:     0,
+		},
+		{
+			name:      "case 5",
+			body:      `{"order":"12345678903","status":"NEW"}`,
+			signature: utils.Sign(secret, []byte(`{"order":"12345678903","status":"NEW"}`)),
+			want:      http.StatusBadRequest,
+			saved:     0,
+		},
+		{
+			name:      "case 6",
+			body:      `{"order":"2377225625","status":"PROCESSING"}`,
+			signature: utils.Sign(secret, []byte(`{"order":"2377225625","status":"PROCESSING"}`)),
+			want:      http.StatusConflict,
+			saved:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{tasks: map[string]models.Task{
+				"12345678903": {OrderID: 1, OrderNumber: "12345678903", Status: "PROCESSING"},
+				"2377225625":  {OrderID: 2, OrderNumber: "2377225625", Status: "PROCESSED", Accrual: 500},
+			}}
+			h := handlers.NewHandler(jwtauth.New("HS256", secret, nil), repo, logger.NewLogger(io.Discard))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/accrual/orders", strings.NewReader(tt.body))
+			req.Header.Set(handlers.AccrualSignatureHeader, tt.signature)
+			w := httptest.NewRecorder()
+
+			h.AccrualWebhook(context.Background(), secret)(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Len(t, repo.saved, tt.saved)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func TestBalanceAt(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	repo := &fakeRepo{
+		statement: []models.StatementEntry{
+			{At: "2020-12-10T15:16:00+03:00", Type: models.StatementAccrual, Order: "12345678903", Amount: 729.98, Balance: 729.98},
+			{At: "2020-12-11T10:00:00+03:00", Type: models.StatementWithdrawal, Order: "2377225624", Amount: -500, Balance: 229.98},
+		},
+	}
+	h := handlers.NewHandler(tokenAuth, repo, logger.NewLogger(io.Discard))
+
+	r := chi.NewRouter()
+	r.Use(jwtauth.Verifier(tokenAuth))
+	r.Use(jwtauth.Authenticator)
+	r.Get("/api/user/balance", h.Balance(context.Background()))
+
+	tests := []struct {
+		name    string
+		at      string
+		want    int
+		balance models.Balace
+	}{
+		{
+			name:    "case 1",
+			at:      "",
+			want:    http.StatusOK,
+			balance: models.Balace{Current: 229.98, Withdrawn: 500},
+		},
+		{
+			name:    "case 2",
+			at:      "2020-12-01T00:00:00Z",
+			want:    http.StatusOK,
+			balance: models.Balace{},
+		},
+		{
+			name:    "case 3",
+			at:      "2020-12-10T15:16:00+03:00",
+			want:    http.StatusOK,
+			balance: models.Balace{Current: 729.98},
+		},
+		{
+			name:    "case 4",
+			at:      "2020-12-11T12:00:00+03:00",
+			want:    http.StatusOK,
+			balance: models.Balace{Current: 229.98, Withdrawn: 500},
+		},
+		{
+			name: "case 5",
+			at:   "2020-12-11",
+			want: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/api/user/balance"
+			if tt.at != "" {
+				target += "?at=" + url.QueryEscape(tt.at)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newAuthRequest(t, tokenAuth, 1, http.MethodGet, target, nil))
+			assert.Equal(t, tt.want, w.Code)
+
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var balance models.Balace
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&balance))
+			assert.Equal(t, tt.balance, balance)
+		})
+	}
+}
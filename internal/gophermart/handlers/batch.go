@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+const (
+	maxBatchSize     = 1000
+	maxBatchBodySize = 1 << 20
+)
+
+var (
+	ErrEmptyBatch        = errors.New("batch contains no order numbers")
+	ErrBatchTooLarge     = fmt.Errorf("batch contains more than %d order numbers", maxBatchSize)
+	ErrBatchBodyTooLarge = fmt.Errorf("batch body is larger than %d bytes", maxBatchBodySize)
+)
+
+func (h *Handler) OrdersBatch(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		numbers, err := getOrderNumbersFromBody(w, r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		results, err := h.orders.UploadBatch(ctx, models.Client{ID: clientID}, numbers)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusOK, results)
+	}
+}
+
+func getOrderNumbersFromBody(w http.ResponseWriter, r *http.Request) (numbers []string, err error) {
+	// Обрезанное тело нельзя разбирать: последняя строка может оказаться
+	// другим, но корректным номером заказа.
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodySize))
+	if err != nil && len(data) >= maxBatchBodySize {
+		return nil, ErrBatchBodyTooLarge
+	}
+
+	if err != nil {
+		return nil, malformed(err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "application/json" || strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err = json.Unmarshal(data, &numbers); err != nil {
+			return nil, malformed(err)
+		}
+	} else {
+		for _, line := range strings.Split(string(data), "\n") {
+			if number := strings.TrimSpace(line); number != "" {
+				numbers = append(numbers, number)
+			}
+		}
+	}
+
+	switch {
+	case len(numbers) == 0:
+		return nil, ErrEmptyBatch
+	case len(numbers) > maxBatchSize:
+		return nil, ErrBatchTooLarge
+	}
+
+	return numbers, nil
+}
@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func TestOrdersBatch(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+		results     []models.OrderUploadResult
+	}{
+		{
+			name:        "case 1",
+			contentType: "application/json",
+			body:        `["12345678903", "2377225624", "9278923470", "1234"]`,
+			want:        http.StatusOK,
+			results: []models.OrderUploadResult{
+				{Number: "12345678903", Result: models.OrderUploadAlreadyUploaded},
+				{Number: "2377225624", Result: models.OrderUploadConflict},
+				{Number: "9278923470", Result: models.OrderUploadAccepted},
+				{Number: "1234", Result: models.OrderUploadInvalid},
+			},
+		},
+		{
+			name:        "case 2",
+			contentType: "text/plain",
+			body:        "12345678903\n\n 9278923470 \r\nabc\n",
+			want:        http.StatusOK,
+			results: []models.OrderUploadResult{
+				{Number: "12345678903", Result: models.OrderUploadAlreadyUploaded},
+				{Number: "9278923470", Result: models.OrderUploadAccepted},
+				{Number: "abc", Result: models.OrderUploadInvalid},
+			},
+		},
+		{
+			name:        "case 3",
+			contentType: "text/plain",
+			body:        "\n\n",
+			want:        http.StatusBadRequest,
+		},
+		{
+			name:        "case 4",
+			contentType: "application/json",
+			body:        `{"order":"12345678903"}`,
+			want:        http.StatusBadRequest,
+		},
+		{
+			name:        "case 5",
+			contentType: "text/plain",
+			body:        strings.Repeat("12345678903\n", 1001),
+			want:        http.StatusBadRequest,
+		},
+		{
+			name:        "case 6",
+			contentType: "text/plain",
+			body:        strings.Repeat(" ", 1<<20-5) + "\n12345678903",
+			want:        http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{
+				orders: []models.Order{
+					{ID: 1, ClientID: 1, Number: "12345678903", Status: "NEW"},
+					{ID: 2, ClientID: 2, Number: "2377225624", Status: "NEW"},
+				},
+			}
+			h := handlers.NewHandler(tokenAuth, repo, logger.NewLogger(io.Discard))
+
+			r := chi.NewRouter()
+			r.Use(jwtauth.Verifier(tokenAuth))
+			r.Use(jwtauth.Authenticator)
+			r.Post("/api/user/orders/batch", h.OrdersBatch(context.Background()))
+
+			req := newAuthRequest(t, tokenAuth, 1, http.MethodPost, "/api/user/orders/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var results []models.OrderUploadResult
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&results))
+			assert.Equal(t, tt.results, results)
+		})
+	}
+}
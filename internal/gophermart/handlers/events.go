@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/events"
+)
+
+var ErrStreamingUnsupported = errors.New("streaming unsupported")
+
+func (h *Handler) Events(ctx context.Context, bus *events.Bus, heartbeat time.Duration) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			h.writeError(w, r, ErrStreamingUnsupported)
+
+			return
+		}
+
+		lastEventID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+		stream, backlog, unsubscribe, err := bus.Resume(r.Context(), clientID, lastEventID)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, "retry: %d\n\n", heartbeat.Milliseconds())
+
+		// Событие может прийти и в backlog, и в канал, поэтому уже
+		// отправленные идентификаторы пропускаются.
+		lastSentID := lastEventID
+
+		for _, event := range backlog {
+			if err = writeEvent(w, event); err != nil {
+				h.mLogger.Warning(err.Error())
+
+				return
+			}
+
+			if event.ID > lastSentID {
+				lastSentID = event.ID
+			}
+		}
+
+		flusher.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-stream:
+				if !ok {
+					return
+				}
+
+				if event.ID <= lastSentID {
+					continue
+				}
+
+				if err = writeEvent(w, event); err != nil {
+					h.mLogger.Warning(err.Error())
+
+					return
+				}
+
+				lastSentID = event.ID
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			case <-r.Context().Done():
+				return
+			case <-ctx.Done():
+				return
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+
+	return err
+}
@@ -0,0 +1,72 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/events"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+)
+
+func TestEvents(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	bus := events.NewBus(16)
+	h := handlers.NewHandler(tokenAuth, &fakeRepo{}, logger.NewLogger(io.Discard))
+
+	r := chi.NewRouter()
+	r.Use(jwtauth.Verifier(tokenAuth))
+	r.Use(jwtauth.Authenticator)
+	r.Get("/api/user/events", h.Events(context.Background(), bus, 10*time.Millisecond))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	bus.Publish(1, events.TypeOrder, events.OrderStatus{Number: "12345678903", Status: "PROCESSING"})
+	bus.Publish(1, events.TypeOrder, events.OrderStatus{Number: "12345678903", Status: "PROCESSED", Accrual: 500})
+
+	req := newAuthRequest(t, tokenAuth, 1, http.MethodGet, server.URL+"/api/user/events", nil)
+	req.RequestURI = ""
+	req.Header.Set("Last-Event-ID", "1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	readUntil := func(prefix string) string {
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimSpace(line)
+			}
+		}
+	}
+
+	assert.Equal(t, "id: 2", readUntil("id:"))
+	assert.Equal(t, `data: {"number":"12345678903","status":"PROCESSED","accrual":500}`, readUntil("data:"))
+	assert.Equal(t, ": heartbeat", readUntil(":"))
+
+	bus.Publish(1, events.TypeBalance, map[string]float64{"current": 500})
+
+	assert.Equal(t, "event: balance", readUntil("event:"))
+}
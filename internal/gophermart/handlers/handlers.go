@@ -4,32 +4,43 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth"
+	"github.com/vukit/gomac/internal/gophermart/auth"
 	"github.com/vukit/gomac/internal/gophermart/logger"
 	"github.com/vukit/gomac/internal/gophermart/models"
 	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
 )
 
 type Handler struct {
 	tokenAuth  *jwtauth.JWTAuth
-	repository repositories.Repo
+	accounts   *services.AccountService
+	orders     *services.OrderService
+	balances   *services.BalanceService
+	statements *services.StatementService
+	webhooks   *services.WebhookService
+	pool       repositories.PoolStater
 	mLogger    *logger.Logger
 }
 
-var ErrNotFindClientID = errors.New("not find client id")
-
 func NewHandler(tokenAuth *jwtauth.JWTAuth, repo repositories.Repo, mLogger *logger.Logger) Handler {
+	pool, _ := repo.(repositories.PoolStater)
+
 	return Handler{
 		tokenAuth:  tokenAuth,
-		repository: repo,
+		accounts:   services.NewAccountService(repo),
+		orders:     services.NewOrderService(repo),
+		balances:   services.NewBalanceService(repo),
+		statements: services.NewStatementService(repo),
+		webhooks:   services.NewWebhookService(repo),
+		pool:       pool,
 		mLogger:    mLogger,
 	}
 }
@@ -59,36 +70,20 @@ func (h *Handler) Register(ctx context.Context) func(w http.ResponseWriter, r *h
 
 		client, err := getClientFromBody(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
-		if err = client.Validate(); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
-
-			return
-		}
-
-		clientID, err := h.repository.SaveClient(ctx, client)
+		clientID, err := h.accounts.Register(ctx, client)
 		if err != nil {
-			switch {
-			case errors.Is(err, repositories.ErrLoginIsAlreadyTaken):
-				w.WriteHeader(http.StatusConflict)
-			default:
-				w.WriteHeader(http.StatusUnauthorized)
-			}
-
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
 		if err = h.setJWToken(w, clientID); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
@@ -103,30 +98,20 @@ func (h *Handler) Login(ctx context.Context) func(w http.ResponseWriter, r *http
 
 		client, err := getClientFromBody(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
-
-			return
-		}
-
-		if err = client.Validate(); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
-		clientID, err := h.repository.FindClient(ctx, client)
+		clientID, err := h.accounts.Login(ctx, client)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
 		if err = h.setJWToken(w, clientID); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
@@ -141,46 +126,29 @@ func (h *Handler) Order(ctx context.Context) func(w http.ResponseWriter, r *http
 
 		clientID, err := getClientID(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
 		data, err := io.ReadAll(r.Body)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, malformed(err))
 
 			return
 		}
 
-		order := models.Order{ClientID: clientID, Number: string(data)}
-
-		if err = order.Validate(); err != nil {
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
-
-			return
-		}
-
-		err = h.repository.SaveOrder(ctx, &order)
+		created, err := h.orders.Upload(ctx, &models.Order{ClientID: clientID, Number: string(data)})
 		if err != nil {
-			switch {
-			case errors.Is(err, repositories.ErrOrderNumberUploadedThisClient):
-				w.WriteHeader(http.StatusOK)
-			case errors.Is(err, repositories.ErrOrderNumberUploadedAnotherClient):
-				w.WriteHeader(http.StatusConflict)
-			default:
-				w.WriteHeader(http.StatusNotAcceptable)
-			}
-
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
-		w.WriteHeader(http.StatusAccepted)
+		// Повторная загрузка своего заказа не является ошибкой.
+		if created {
+			w.WriteHeader(http.StatusAccepted)
+		}
 
 		fmt.Fprintf(w, "{}")
 	}
@@ -192,37 +160,54 @@ func (h *Handler) Orders(ctx context.Context) func(w http.ResponseWriter, r *htt
 
 		clientID, err := getClientID(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
-		orders, err := h.repository.FindOrders(ctx, models.Client{ID: clientID})
-		if err != nil || len(orders) == 0 {
+		// Без параметров отдаём полный список, как и раньше.
+		if r.URL.RawQuery != "" {
+			h.ordersPage(ctx, w, r, clientID)
+
+			return
+		}
+
+		orders, err := h.orders.Orders(ctx, models.Client{ID: clientID})
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		if len(orders) == 0 {
 			w.WriteHeader(http.StatusNoContent)
 
 			return
 		}
 
-		body := &bytes.Buffer{}
-		encoder := json.NewEncoder(body)
+		h.writeJSON(w, r, http.StatusOK, orders)
+	}
+}
+
+func (h *Handler) OrderTimeline(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
-		err = encoder.Encode(orders)
+		clientID, err := getClientID(r)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
-		_, err = w.Write(body.Bytes())
+		details, err := h.orders.Order(ctx, models.Client{ID: clientID}, chi.URLParam(r, "number"))
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
+
+		h.writeJSON(w, r, http.StatusOK, details)
 	}
 }
 
@@ -232,8 +217,7 @@ func (h *Handler) Withdraw(ctx context.Context) func(w http.ResponseWriter, r *h
 
 		clientID, err := getClientID(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
@@ -244,29 +228,13 @@ func (h *Handler) Withdraw(ctx context.Context) func(w http.ResponseWriter, r *h
 
 		err = decoder.Decode(&withdrawal)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, malformed(err))
 
 			return
 		}
 
-		if err = withdrawal.Validate(); err != nil {
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
-
-			return
-		}
-
-		err = h.repository.SaveWithdrawal(ctx, &withdrawal)
-		if err != nil {
-			switch {
-			case errors.Is(err, repositories.ErrThereAreNotEnoughAccrual):
-				w.WriteHeader(http.StatusPaymentRequired)
-			default:
-				w.WriteHeader(http.StatusNotAcceptable)
-			}
-
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+		if _, err = h.balances.Withdraw(ctx, withdrawal); err != nil {
+			h.writeError(w, r, err)
 
 			return
 		}
@@ -281,37 +249,32 @@ func (h *Handler) Withdrawals(ctx context.Context) func(w http.ResponseWriter, r
 
 		clientID, err := getClientID(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
-		withdrawals, err := h.repository.FindWithdrawals(ctx, models.Client{ID: clientID})
-		if err != nil || len(withdrawals) == 0 {
-			w.WriteHeader(http.StatusNoContent)
+		// Без параметров отдаём полный список, как и раньше.
+		if r.URL.RawQuery != "" {
+			h.withdrawalsPage(ctx, w, r, clientID)
 
 			return
 		}
 
-		body := &bytes.Buffer{}
-		encoder := json.NewEncoder(body)
-
-		err = encoder.Encode(withdrawals)
+		withdrawals, err := h.balances.Withdrawals(ctx, models.Client{ID: clientID})
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
-		_, err = w.Write(body.Bytes())
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+		if len(withdrawals) == 0 {
+			w.WriteHeader(http.StatusNoContent)
 
 			return
 		}
+
+		h.writeJSON(w, r, http.StatusOK, withdrawals)
 	}
 }
 
@@ -321,52 +284,49 @@ func (h *Handler) Balance(ctx context.Context) func(w http.ResponseWriter, r *ht
 
 		clientID, err := getClientID(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+			h.writeError(w, r, err)
 
 			return
 		}
 
-		balance, err := h.repository.FindBalance(ctx, models.Client{ID: clientID})
+		balance, err := h.findBalance(ctx, r, clientID)
 		if err != nil {
-			w.WriteHeader(http.StatusNoContent)
+			h.writeError(w, r, err)
 
 			return
 		}
 
-		body := &bytes.Buffer{}
-		encoder := json.NewEncoder(body)
-
-		err = encoder.Encode(balance)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+		h.writeJSON(w, r, http.StatusOK, balance)
+	}
+}
 
-			return
-		}
+// findBalance возвращает текущий баланс, а с параметром at — баланс,
+// восстановленный на указанный момент.
+func (h *Handler) findBalance(ctx context.Context, r *http.Request, clientID int) (*models.Balace, error) {
+	var at time.Time
 
-		_, err = w.Write(body.Bytes())
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(w, "{\"error\":%q}\n", err)
+	if value := r.URL.Query().Get("at"); value != "" {
+		var err error
 
-			return
+		if at, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, models.ErrInvalidBalanceTime
 		}
 	}
+
+	return h.balances.Balance(ctx, models.Client{ID: clientID}, at)
 }
 
 func getClientFromBody(r *http.Request) (client models.Client, err error) {
 	decoder := json.NewDecoder(r.Body)
-	err = decoder.Decode(&client)
+	if err = decoder.Decode(&client); err != nil {
+		return client, malformed(err)
+	}
 
 	return
 }
 
 func (h *Handler) setJWToken(w http.ResponseWriter, clientID int) (err error) {
-	claims := map[string]interface{}{"client_id": strconv.Itoa(clientID)}
-	jwtauth.SetExpiry(claims, time.Now().Add(time.Hour))
-
-	_, tokenString, err := h.tokenAuth.Encode(claims)
+	tokenString, err := auth.NewToken(h.tokenAuth, clientID)
 	if err == nil {
 		http.SetCookie(w, &http.Cookie{Name: "jwt", Value: tokenString, Path: "/"})
 	}
@@ -375,20 +335,33 @@ func (h *Handler) setJWToken(w http.ResponseWriter, clientID int) (err error) {
 }
 
 func getClientID(r *http.Request) (id int, err error) {
-	_, claims, err := jwtauth.FromContext(r.Context())
-	if err != nil {
-		return 0, err
-	}
+	return auth.ClientIDFromContext(r.Context())
+}
 
-	clientID, ok := claims["client_id"]
-	if !ok {
-		return 0, ErrNotFindClientID
-	}
+// Authenticator заменяет jwtauth.Authenticator, чтобы ошибка авторизации
+// возвращалась в формате problem+json.
+func Authenticator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, _, err := jwtauth.FromContext(r.Context()); err != nil || token == nil {
+			writeProblem(w, r, NewProblem(auth.ErrUnauthorized))
 
-	id, err = strconv.Atoi(clientID.(string))
-	if err != nil {
-		return 0, ErrNotFindClientID
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, r *http.Request, statusCode int, v interface{}) {
+	body := &bytes.Buffer{}
+
+	if err := json.NewEncoder(body).Encode(v); err != nil {
+		h.writeError(w, r, err)
+
+		return
 	}
 
-	return
+	w.WriteHeader(statusCode)
+
+	_, _ = w.Write(body.Bytes())
 }
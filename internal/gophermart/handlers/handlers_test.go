@@ -1,9 +1,283 @@
 package handlers_test
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
 )
 
+// fakeRepo сам служит транзакцией: InTx передаёт его в fn.
+type fakeRepo struct {
+	repositories.Repo
+	repositories.Tx
+	tasks  map[string]models.Task
+	saved  []models.OrderEvent
+	orders []models.Order
+	events map[int][]models.OrderEvent
+	query  *models.ListQuery
+
+	statement    []models.StatementEntry
+	statementErr error
+	monthly      []models.MonthlyStatement
+}
+
+func (r *fakeRepo) FindTask(ctx context.Context, number string) (models.Task, error) {
+	task, ok := r.tasks[number]
+	if !ok {
+		return task, repositories.ErrOrderNotFound
+	}
+
+	return task, nil
+}
+
+func (r *fakeRepo) InTx(ctx context.Context, fn func(repositories.Tx) error) error {
+	return fn(r)
+}
+
+func (r *fakeRepo) FindTaskForUpdate(ctx context.Context, number string) (models.Task, error) {
+	return r.FindTask(ctx, number)
+}
+
+func (r *fakeRepo) UpdateTask(ctx context.Context, task models.Task) error {
+	r.tasks[task.OrderNumber] = task
+
+	return nil
+}
+
+func (r *fakeRepo) SaveOrderEvent(ctx context.Context, orderID int, event models.OrderEvent) error {
+	r.saved = append(r.saved, event)
+
+	return nil
+}
+
+func (r *fakeRepo) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) error {
+	return nil
+}
+
+func (r *fakeRepo) FindOrder(ctx context.Context, client models.Client, number string) (models.Order, error) {
+	for _, order := range r.orders {
+		if order.ClientID == client.ID && order.Number == number {
+			return order, nil
+		}
+	}
+
+	return models.Order{}, repositories.ErrOrderNotFound
+}
+
+func (r *fakeRepo) InsertOrder(ctx context.Context, order *models.Order) error {
+	for _, stored := range r.orders {
+		if stored.Number != order.Number {
+			continue
+		}
+
+		if stored.ClientID == order.ClientID {
+			return repositories.ErrOrderNumberUploadedThisClient
+		}
+
+		return repositories.ErrOrderNumberUploadedAnotherClient
+	}
+
+	order.Status = models.StatusNew
+	r.orders = append(r.orders, *order)
+
+	return nil
+}
+
+func (r *fakeRepo) FindOrders(ctx context.Context, client models.Client) ([]models.Order, error) {
+	orders := make([]models.Order, 0)
+
+	for _, order := range r.orders {
+		if order.ClientID == client.ID {
+			orders = append(orders, order)
+		}
+	}
+
+	return orders, nil
+}
+
+func (r *fakeRepo) FindOrdersPage(ctx context.Context, client models.Client, query models.ListQuery) (page models.OrdersPage, err error) {
+	r.query = &query
+
+	// В качестве идентификатора курсора фейк хранит смещение.
+	offset := 0
+	if query.After != nil {
+		offset = query.After.ID
+	}
+
+	orders, _ := r.FindOrders(ctx, client)
+	orders = orders[offset:]
+
+	if len(orders) > query.Limit {
+		orders = orders[:query.Limit]
+		page.Next = &models.Cursor{At: time.Now(), ID: offset + query.Limit}
+	}
+
+	page.Orders = orders
+
+	return page, nil
+}
+
+func (r *fakeRepo) FindWithdrawalsPage(ctx context.Context, client models.Client, query models.ListQuery) (models.WithdrawalsPage, error) {
+	r.query = &query
+
+	return models.WithdrawalsPage{}, nil
+}
+
+func (r *fakeRepo) StreamStatement(ctx context.Context, client models.Client, from, to time.Time, fn func(models.StatementEntry) error) error {
+	for _, entry := range r.statement {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return r.statementErr
+}
+
+func (r *fakeRepo) FindStatements(ctx context.Context, client models.Client) ([]models.MonthlyStatement, error) {
+	statements := make([]models.MonthlyStatement, 0)
+
+	for _, statement := range r.monthly {
+		if statement.ClientID == client.ID {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements, nil
+}
+
+func (r *fakeRepo) FindStatement(ctx context.Context, client models.Client, period time.Time) (models.MonthlyStatement, error) {
+	for _, statement := range r.monthly {
+		if statement.ClientID == client.ID && statement.Period == period.Format(models.StatementPeriodLayout) {
+			return statement, nil
+		}
+	}
+
+	return models.MonthlyStatement{}, repositories.ErrStatementNotFound
+}
+
+func (r *fakeRepo) FindBalance(ctx context.Context, client models.Client) (*models.Balace, error) {
+	return r.FindBalanceAt(ctx, client, time.Now())
+}
+
+// FindBalanceAt считает баланс по выписке фейка.
+func (r *fakeRepo) FindBalanceAt(ctx context.Context, client models.Client, at time.Time) (*models.Balace, error) {
+	balance := &models.Balace{}
+
+	for _, entry := range r.statement {
+		entryAt, err := time.Parse(time.RFC3339, entry.At)
+		if err != nil {
+			return nil, err
+		}
+
+		if entryAt.After(at) {
+			break
+		}
+
+		balance.Current = entry.Balance
+		if entry.Type == models.StatementWithdrawal {
+			balance.Withdrawn -= entry.Amount
+		}
+	}
+
+	return balance, nil
+}
+
+func (r *fakeRepo) FindOrderEvents(ctx context.Context, order models.Order) ([]models.OrderEvent, error) {
+	return r.events[order.ID], nil
+}
+
+func newAuthRequest(t *testing.T, tokenAuth *jwtauth.JWTAuth, clientID int, method, target string, body io.Reader) *http.Request {
+	t.Helper()
+
+	_, token, err := tokenAuth.Encode(map[string]interface{}{"client_id": strconv.Itoa(clientID)})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, body)
+	req.AddCookie(&http.Cookie{Name: "jwt", Value: token})
+
+	return req
+}
+
 func TestHandlers(t *testing.T) {
 	t.Skip() // проверятся интеграционным тестом на Github
 }
+
+func TestOrderTimeline(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	repo := &fakeRepo{
+		orders: []models.Order{
+			{ID: 1, ClientID: 1, Number: "12345678903", Status: "PROCESSED", Accrual: 500, UploadedAt: "2020-12-10T15:15:45+03:00"},
+		},
+		events: map[int][]models.OrderEvent{
+			1: {
+				{Event: models.OrderEventUploaded, Status: "NEW", CreatedAt: "2020-12-10T15:15:45+03:00"},
+				{Event: models.OrderEventPickedUp, StatusFrom: "NEW", Status: "PROCESSING", CreatedAt: "2020-12-10T15:15:46+03:00"},
+				{Event: models.OrderEventFinal, StatusFrom: "PROCESSING", Status: "PROCESSED", Accrual: 500, CreatedAt: "2020-12-10T15:16:00+03:00"},
+			},
+		},
+	}
+	h := handlers.NewHandler(tokenAuth, repo, logger.NewLogger(io.Discard))
+
+	r := chi.NewRouter()
+	r.Use(jwtauth.Verifier(tokenAuth))
+	r.Use(jwtauth.Authenticator)
+	r.Get("/api/user/orders/{number}", h.OrderTimeline(context.Background()))
+
+	tests := []struct {
+		name     string
+		clientID int
+		number   string
+		want     int
+	}{
+		{
+			name:     "case 1",
+			clientID: 1,
+			number:   "12345678903",
+			want:     http.StatusOK,
+		},
+		{
+			name:     "case 2",
+			clientID: 2,
+			number:   "12345678903",
+			want:     http.StatusNotFound,
+		},
+		{
+			name:     "case 3",
+			clientID: 1,
+			number:   "2377225624",
+			want:     http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newAuthRequest(t, tokenAuth, tt.clientID, http.MethodGet, "/api/user/orders/"+tt.number, nil))
+			assert.Equal(t, tt.want, w.Code)
+
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var details models.OrderDetails
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&details))
+			assert.Equal(t, models.StatusProcessed, details.Status)
+			assert.Len(t, details.Timeline, 3)
+			assert.Equal(t, models.OrderEventFinal, details.Timeline[2].Event)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	ErrInvalidSort           = errors.New("sort must be asc or desc")
+	ErrUnsupportedListFilter = errors.New("withdrawals cannot be filtered by status or min_accrual")
+)
+
+func (h *Handler) ordersPage(ctx context.Context, w http.ResponseWriter, r *http.Request, clientID int) {
+	query, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	page, err := h.orders.OrdersPage(ctx, models.Client{ID: clientID}, query)
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	if len(page.Orders) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	setLinkHeader(w, r, page.Next)
+	h.writeJSON(w, r, http.StatusOK, page.Orders)
+}
+
+func (h *Handler) withdrawalsPage(ctx context.Context, w http.ResponseWriter, r *http.Request, clientID int) {
+	query, err := parseWithdrawalsQuery(r.URL.Query())
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	page, err := h.balances.WithdrawalsPage(ctx, models.Client{ID: clientID}, query)
+	if err != nil {
+		h.writeError(w, r, err)
+
+		return
+	}
+
+	if len(page.Withdrawals) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	setLinkHeader(w, r, page.Next)
+	h.writeJSON(w, r, http.StatusOK, page.Withdrawals)
+}
+
+func parseListQuery(values url.Values) (query models.ListQuery, err error) {
+	query.Limit = models.DefaultPageLimit
+
+	if value := values.Get("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil {
+			return query, models.ErrInvalidPageLimit
+		}
+	}
+
+	if value := values.Get("cursor"); value != "" {
+		cursor, err := models.DecodeCursor(value)
+		if err != nil {
+			return query, err
+		}
+
+		query.After = &cursor
+	}
+
+	for _, value := range values["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.ToUpper(strings.TrimSpace(status)); status != "" {
+				query.Statuses = append(query.Statuses, models.OrderStatus(status))
+			}
+		}
+	}
+
+	if query.From, err = parseDate(values.Get("from"), false); err != nil {
+		return query, err
+	}
+
+	if query.To, err = parseDate(values.Get("to"), true); err != nil {
+		return query, err
+	}
+
+	if value := values.Get("min_accrual"); value != "" {
+		if query.MinAccrual, err = strconv.ParseFloat(value, 64); err != nil {
+			return query, models.ErrInvalidMinAccrual
+		}
+	}
+
+	switch strings.ToLower(values.Get("sort")) {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return query, ErrInvalidSort
+	}
+
+	return query, query.Validate()
+}
+
+// parseWithdrawalsQuery не принимает фильтры заказов: у списаний нет статуса
+// и начисления, а молча отдать нефильтрованную страницу нельзя.
+func parseWithdrawalsQuery(values url.Values) (query models.ListQuery, err error) {
+	if values.Has("status") || values.Has("min_accrual") {
+		return query, ErrUnsupportedListFilter
+	}
+
+	return parseListQuery(values)
+}
+
+// parseDate принимает дату в формате RFC3339 или YYYY-MM-DD; дата без времени
+// в качестве верхней границы включает весь день.
+func parseDate(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return date, models.ErrInvalidDateRange
+	}
+
+	if end {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return date, nil
+}
+
+func setLinkHeader(w http.ResponseWriter, r *http.Request, next *models.Cursor) {
+	if next == nil {
+		return
+	}
+
+	w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", nextLink(r, next)))
+}
+
+func nextLink(r *http.Request, next *models.Cursor) string {
+	values := r.URL.Query()
+	values.Set("cursor", next.Encode())
+
+	link := url.URL{Path: r.URL.Path, RawQuery: values.Encode()}
+
+	return link.String()
+}
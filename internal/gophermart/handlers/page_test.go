@@ -0,0 +1,197 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func TestOrdersPage(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	repo := &fakeRepo{
+		orders: []models.Order{
+			{ID: 1, ClientID: 1, Number: "12345678903", Status: "PROCESSED", Accrual: 500},
+			{ID: 2, ClientID: 1, Number: "9278923470", Status: "NEW"},
+			{ID: 3, ClientID: 1, Number: "346436439", Status: "INVALID"},
+		},
+	}
+	h := handlers.NewHandler(tokenAuth, repo, logger.NewLogger(io.Discard))
+
+	r := chi.NewRouter()
+	r.Use(jwtauth.Verifier(tokenAuth))
+	r.Use(jwtauth.Authenticator)
+	r.Get("/api/user/orders", h.Orders(context.Background()))
+
+	tests := []struct {
+		name   string
+		target string
+		want   int
+		count  int
+		next   bool
+		paged  bool
+	}{
+		{
+			name:   "case 1",
+			target: "/api/user/orders",
+			want:   http.StatusOK,
+			count:  3,
+		},
+		{
+			name:   "case 2",
+			target: "/api/user/orders?limit=2&status=new,processed&from=2020-12-01&to=2020-12-31&min_accrual=10&sort=desc",
+			want:   http.StatusOK,
+			count:  2,
+			next:   true,
+			paged:  true,
+		},
+		{
+			name:   "case 3",
+			target: "/api/user/orders?limit=2&cursor=" + (&models.Cursor{ID: 2}).Encode(),
+			want:   http.StatusOK,
+			count:  1,
+			paged:  true,
+		},
+		{
+			name:   "case 4",
+			target: "/api/user/orders?limit=0",
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "case 5",
+			target: "/api/user/orders?cursor=abc",
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "case 6",
+			target: "/api/user/orders?status=DONE",
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "case 7",
+			target: "/api/user/orders?sort=up",
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "case 8",
+			target: "/api/user/orders?from=yesterday",
+			want:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.query = nil
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newAuthRequest(t, tokenAuth, 1, http.MethodGet, tt.target, nil))
+			assert.Equal(t, tt.want, w.Code)
+
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var orders []models.Order
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&orders))
+			assert.Len(t, orders, tt.count)
+			assert.Equal(t, tt.paged, repo.query != nil)
+
+			link := w.Header().Get("Link")
+			if !tt.next {
+				assert.Empty(t, link)
+
+				return
+			}
+
+			assert.True(t, strings.HasPrefix(link, "</api/user/orders?"))
+			assert.True(t, strings.HasSuffix(link, `>; rel="next"`))
+			assert.Contains(t, link, "cursor=")
+			assert.Contains(t, link, "limit=2")
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newAuthRequest(t, tokenAuth, 1, http.MethodGet, "/api/user/orders?status=NEW,PROCESSED&from=2020-12-01&to=2020-12-31&min_accrual=10&sort=desc", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []models.OrderStatus{models.StatusNew, models.StatusProcessed}, repo.query.Statuses)
+	assert.Equal(t, "2020-12-01T00:00:00Z", repo.query.From.Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, "2021-01-01T00:00:00Z", repo.query.To.Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, 10.0, repo.query.MinAccrual)
+	assert.Equal(t, models.DefaultPageLimit, repo.query.Limit)
+	assert.True(t, repo.query.Desc)
+}
+
+func TestWithdrawalsPage(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	repo := &fakeRepo{}
+	h := handlers.NewHandler(tokenAuth, repo, logger.NewLogger(io.Discard))
+
+	r := chi.NewRouter()
+	r.Use(jwtauth.Verifier(tokenAuth))
+	r.Use(jwtauth.Authenticator)
+	r.Get("/api/user/withdrawals", h.Withdrawals(context.Background()))
+	r.Get(handlers.APIv2Prefix+"/withdrawals", h.WithdrawalsV2(context.Background()))
+
+	tests := []struct {
+		name   string
+		target string
+		want   int
+		code   string
+	}{
+		{
+			name:   "case 1",
+			target: "/api/user/withdrawals?limit=2&from=2020-12-01&sort=desc",
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "case 2",
+			target: "/api/user/withdrawals?status=NEW",
+			want:   http.StatusBadRequest,
+			code:   "unsupported_list_filter",
+		},
+		{
+			name:   "case 3",
+			target: "/api/user/withdrawals?min_accrual=10",
+			want:   http.StatusBadRequest,
+			code:   "unsupported_list_filter",
+		},
+		{
+			name:   "case 4",
+			target: handlers.APIv2Prefix + "/withdrawals?status=PROCESSED",
+			want:   http.StatusBadRequest,
+			code:   "unsupported_list_filter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.query = nil
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newAuthRequest(t, tokenAuth, 1, http.MethodGet, tt.target, nil))
+			assert.Equal(t, tt.want, w.Code)
+
+			if tt.code == "" {
+				assert.NotNil(t, repo.query)
+
+				return
+			}
+
+			var problem handlers.Problem
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Nil(t, repo.query)
+		})
+	}
+}
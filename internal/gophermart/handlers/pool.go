@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var ErrPoolStatsUnsupported = errors.New("storage has no connection pool")
+
+func (h *Handler) PoolStats(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		if h.pool == nil {
+			h.writeError(w, r, ErrPoolStatsUnsupported)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusOK, h.pool.PoolStats())
+	}
+}
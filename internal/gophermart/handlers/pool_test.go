@@ -0,0 +1,59 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+type pooledRepo struct {
+	repositories.Repo
+}
+
+func (r *pooledRepo) PoolStats() models.PoolStats {
+	return models.PoolStats{MaxOpenConnections: 20, OpenConnections: 3, InUse: 2, Idle: 1}
+}
+
+func TestPoolStats(t *testing.T) {
+	tests := []struct {
+		name string
+		repo repositories.Repo
+		want int
+		body string
+	}{
+		{
+			name: "case 1",
+			repo: &pooledRepo{},
+			want: http.StatusOK,
+			body: `"max_open_connections":20,"open_connections":3,"in_use":2,"idle":1`,
+		},
+		{
+			name: "case 2",
+			repo: repositories.NewRepositoryMemory(),
+			want: http.StatusNotImplemented,
+			body: `"code":"pool_stats_unsupported"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handlers.NewHandler(nil, tt.repo, logger.NewLogger(io.Discard))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/db/stats", nil)
+			w := httptest.NewRecorder()
+
+			h.PoolStats(context.Background())(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Contains(t, w.Body.String(), tt.body)
+		})
+	}
+}
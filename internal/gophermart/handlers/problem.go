@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/vukit/gomac/internal/gophermart/problems"
+)
+
+const (
+	ProblemContentType = problems.ContentType
+	ProblemTypePrefix  = problems.TypePrefix
+
+	ProblemInternalError = problems.InternalError
+)
+
+type Problem = problems.Problem
+
+// problemKinds — ошибки HTTP API, которых нет в общей таблице problems.
+var problemKinds = []problems.Kind{
+	{Err: ErrInvalidAdminToken, Status: http.StatusUnauthorized, Code: "invalid_admin_token"},
+	{Err: ErrInvalidSignature, Status: http.StatusUnauthorized, Code: "invalid_signature"},
+	{Err: ErrInvalidWebhookID, Status: http.StatusBadRequest, Code: "invalid_webhook_id"},
+	{Err: ErrEmptyBatch, Status: http.StatusBadRequest, Code: "empty_batch"},
+	{Err: ErrBatchTooLarge, Status: http.StatusBadRequest, Code: "batch_too_large"},
+	{Err: ErrBatchBodyTooLarge, Status: http.StatusRequestEntityTooLarge, Code: "batch_body_too_large"},
+	{Err: ErrInvalidSort, Status: http.StatusBadRequest, Code: "invalid_sort"},
+	{Err: ErrUnsupportedListFilter, Status: http.StatusBadRequest, Code: "unsupported_list_filter"},
+	{Err: ErrInvalidStatementFormat, Status: http.StatusBadRequest, Code: "invalid_statement_format"},
+	{Err: ErrInvalidOrderID, Status: http.StatusBadRequest, Code: "invalid_order_id"},
+	{Err: ErrStreamingUnsupported, Status: http.StatusInternalServerError, Code: "streaming_unsupported"},
+	{Err: ErrPoolStatsUnsupported, Status: http.StatusNotImplemented, Code: "pool_stats_unsupported"},
+}
+
+func NewProblem(err error) Problem {
+	return problems.New(err, problemKinds...)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := NewProblem(err)
+
+	if problem.Status >= http.StatusInternalServerError {
+		h.mLogger.Warning(fmt.Sprintf("%s %s: %s", r.Method, r.URL.Path, err))
+	}
+
+	writeProblem(w, r, problem)
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	problem.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+func malformed(err error) error {
+	return fmt.Errorf("%w: %v", problems.ErrMalformedRequest, err)
+}
@@ -0,0 +1,191 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{
+			name:   "case 1",
+			err:    repositories.ErrLoginIsAlreadyTaken,
+			status: http.StatusConflict,
+			code:   "login_taken",
+		},
+		{
+			name:   "case 2",
+			err:    fmt.Errorf("save withdrawal: %w", repositories.ErrThereAreNotEnoughAccrual),
+			status: http.StatusPaymentRequired,
+			code:   "insufficient_balance",
+		},
+		{
+			name:   "case 3",
+			err:    models.ErrInvalidOrderNumberFormat,
+			status: http.StatusUnprocessableEntity,
+			code:   "invalid_order_number",
+		},
+		{
+			name:   "case 4",
+			err:    repositories.ErrNoDBConn,
+			status: http.StatusServiceUnavailable,
+			code:   "database_unavailable",
+		},
+		{
+			name:   "case 5",
+			err:    errors.New("pq: connection reset"),
+			status: http.StatusInternalServerError,
+			code:   handlers.ProblemInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := handlers.NewProblem(tt.err)
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, handlers.ProblemTypePrefix+tt.code, problem.Type)
+			assert.Equal(t, http.StatusText(tt.status), problem.Title)
+
+			if tt.status == http.StatusInternalServerError {
+				assert.Empty(t, problem.Detail)
+			} else {
+				assert.Equal(t, tt.err.Error(), problem.Detail)
+			}
+		})
+	}
+}
+
+type failingRepo struct {
+	repositories.Repo
+	err error
+}
+
+func (r *failingRepo) SaveClient(ctx context.Context, client models.Client) (int, error) {
+	return 0, r.err
+}
+
+func (r *failingRepo) InTx(ctx context.Context, fn func(repositories.Tx) error) error {
+	return r.err
+}
+
+func TestProblemResponses(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+
+	tests := []struct {
+		name      string
+		err       error
+		target    string
+		body      string
+		auth      bool
+		want      int
+		code      string
+		noProblem bool
+	}{
+		{
+			name:   "case 1",
+			err:    errors.New("connection refused"),
+			target: "/api/user/register",
+			body:   `{"login":"user","password":"secret"}`,
+			want:   http.StatusInternalServerError,
+			code:   handlers.ProblemInternalError,
+		},
+		{
+			name:   "case 2",
+			target: "/api/user/register",
+			body:   `{"login":`,
+			want:   http.StatusBadRequest,
+			code:   "malformed_request",
+		},
+		{
+			name:   "case 3",
+			err:    errors.New("connection refused"),
+			target: "/api/user/orders",
+			body:   "12345678903",
+			auth:   true,
+			want:   http.StatusInternalServerError,
+			code:   handlers.ProblemInternalError,
+		},
+		{
+			name:   "case 4",
+			err:    repositories.ErrOrderNumberUploadedAnotherClient,
+			target: "/api/user/orders",
+			body:   "12345678903",
+			auth:   true,
+			want:   http.StatusConflict,
+			code:   "order_uploaded_by_another_client",
+		},
+		{
+			name:      "case 5",
+			err:       repositories.ErrOrderNumberUploadedThisClient,
+			target:    "/api/user/orders",
+			body:      "12345678903",
+			auth:      true,
+			want:      http.StatusOK,
+			noProblem: true,
+		},
+		{
+			name:   "case 6",
+			target: "/api/user/orders",
+			body:   "12345678903",
+			want:   http.StatusUnauthorized,
+			code:   "unauthorized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handlers.NewHandler(tokenAuth, &failingRepo{err: tt.err}, logger.NewLogger(io.Discard))
+
+			r := chi.NewRouter()
+			r.Post("/api/user/register", h.Register(context.Background()))
+			r.Group(func(r chi.Router) {
+				r.Use(jwtauth.Verifier(tokenAuth))
+				r.Use(handlers.Authenticator)
+				r.Post("/api/user/orders", h.Order(context.Background()))
+			})
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.auth {
+				req = newAuthRequest(t, tokenAuth, 1, http.MethodPost, tt.target, strings.NewReader(tt.body))
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+
+			if tt.noProblem {
+				return
+			}
+
+			assert.Equal(t, handlers.ProblemContentType, w.Header().Get("Content-Type"))
+
+			var problem handlers.Problem
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+			assert.Equal(t, tt.want, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, tt.target, problem.Instance)
+		})
+	}
+}
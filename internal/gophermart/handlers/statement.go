@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+const (
+	StatementFormatCSV   = "csv"
+	StatementFormatJSONL = "jsonl"
+)
+
+var ErrInvalidStatementFormat = errors.New("format must be csv or jsonl")
+
+type statementWriter interface {
+	Begin() error
+	Write(models.StatementEntry) error
+	Flush() error
+}
+
+type csvStatementWriter struct {
+	writer *csv.Writer
+}
+
+func (r *csvStatementWriter) Begin() error {
+	return r.writer.Write([]string{"at", "type", "order", "amount", "balance"})
+}
+
+func (r *csvStatementWriter) Write(entry models.StatementEntry) error {
+	return r.writer.Write([]string{
+		entry.At,
+		entry.Type,
+		entry.Order,
+		strconv.FormatFloat(entry.Amount, 'f', -1, 64),
+		strconv.FormatFloat(entry.Balance, 'f', -1, 64),
+	})
+}
+
+func (r *csvStatementWriter) Flush() error {
+	r.writer.Flush()
+
+	return r.writer.Error()
+}
+
+type jsonlStatementWriter struct {
+	encoder *json.Encoder
+}
+
+func (r *jsonlStatementWriter) Begin() error {
+	return nil
+}
+
+func (r *jsonlStatementWriter) Write(entry models.StatementEntry) error {
+	return r.encoder.Encode(entry)
+}
+
+func (r *jsonlStatementWriter) Flush() error {
+	return nil
+}
+
+func (h *Handler) Statement(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		format, from, to, err := parseStatementQuery(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		// Заголовки отправляются с первой строкой выписки, чтобы ошибку
+		// запроса к базе ещё можно было вернуть с кодом 500.
+		var out statementWriter
+
+		start := func() error {
+			switch format {
+			case StatementFormatJSONL:
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				out = &jsonlStatementWriter{encoder: json.NewEncoder(w)}
+			default:
+				w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+				out = &csvStatementWriter{writer: csv.NewWriter(w)}
+			}
+
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"statement.%s\"", format))
+			w.WriteHeader(http.StatusOK)
+
+			return out.Begin()
+		}
+
+		err = h.statements.Stream(ctx, models.Client{ID: clientID}, from, to, func(entry models.StatementEntry) error {
+			if out == nil {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+
+			return out.Write(entry)
+		})
+
+		if err != nil && out == nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		if err == nil && out == nil {
+			err = start()
+		}
+
+		if err == nil {
+			err = out.Flush()
+		}
+
+		// Выписка уже частично отправлена, сообщить об ошибке клиенту нельзя.
+		if err != nil {
+			h.mLogger.Warning(fmt.Sprintf("statement for client %d: %s", clientID, err))
+		}
+	}
+}
+
+func parseStatementQuery(r *http.Request) (format string, from, to time.Time, err error) {
+	values := r.URL.Query()
+
+	switch format = values.Get("format"); format {
+	case "":
+		format = StatementFormatCSV
+	case StatementFormatCSV, StatementFormatJSONL:
+	default:
+		return format, from, to, ErrInvalidStatementFormat
+	}
+
+	if from, err = parseDate(values.Get("from"), false); err != nil {
+		return format, from, to, err
+	}
+
+	to, err = parseDate(values.Get("to"), true)
+
+	return format, from, to, err
+}
+
+func (h *Handler) MonthlyStatements(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		statements, err := h.statements.Monthly(ctx, models.Client{ID: clientID})
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		if len(statements) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusOK, statements)
+	}
+}
+
+func (h *Handler) MonthlyStatement(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		period, err := models.ParseStatementPeriod(chi.URLParam(r, "period"))
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		statement, err := h.statements.Month(ctx, models.Client{ID: clientID}, period)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusOK, statement)
+	}
+}
@@ -0,0 +1,170 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func TestStatement(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	entries := []models.StatementEntry{
+		{At: "2020-12-10T15:16:00+03:00", Type: models.StatementAccrual, Order: "12345678903", Amount: 729.98, Balance: 729.98},
+		{At: "2020-12-11T10:00:00+03:00", Type: models.StatementWithdrawal, Order: "2377225624", Amount: -500, Balance: 229.98},
+	}
+
+	tests := []struct {
+		name        string
+		target      string
+		entries     []models.StatementEntry
+		err         error
+		want        int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "case 1",
+			target:      "/api/user/statement?from=2020-12-01&to=2020-12-31",
+			entries:     entries,
+			want:        http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			body: "at,type,order,amount,balance\n" +
+				"2020-12-10T15:16:00+03:00,accrual,12345678903,729.98,729.98\n" +
+				"2020-12-11T10:00:00+03:00,withdrawal,2377225624,-500,229.98\n",
+		},
+		{
+			name:        "case 2",
+			target:      "/api/user/statement?format=jsonl",
+			entries:     entries,
+			want:        http.StatusOK,
+			contentType: "application/x-ndjson",
+			body: `{"at":"2020-12-10T15:16:00+03:00","type":"accrual","order":"12345678903","amount":729.98,"balance":729.98}` + "\n" +
+				`{"at":"2020-12-11T10:00:00+03:00","type":"withdrawal","order":"2377225624","amount":-500,"balance":229.98}` + "\n",
+		},
+		{
+			name:        "case 3",
+			target:      "/api/user/statement?format=csv",
+			want:        http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			body:        "at,type,order,amount,balance\n",
+		},
+		{
+			name:   "case 4",
+			target: "/api/user/statement?format=xml",
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "case 5",
+			target: "/api/user/statement?from=2020-12-31&to=2020-12-01",
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "case 6",
+			target: "/api/user/statement",
+			err:    errors.New("connection refused"),
+			want:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{statement: tt.entries, statementErr: tt.err}
+			h := handlers.NewHandler(tokenAuth, repo, logger.NewLogger(io.Discard))
+
+			r := chi.NewRouter()
+			r.Use(jwtauth.Verifier(tokenAuth))
+			r.Use(jwtauth.Authenticator)
+			r.Get("/api/user/statement", h.Statement(context.Background()))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newAuthRequest(t, tokenAuth, 1, http.MethodGet, tt.target, nil))
+			assert.Equal(t, tt.want, w.Code)
+
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestMonthlyStatements(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	repo := &fakeRepo{
+		monthly: []models.MonthlyStatement{
+			{ID: 1, ClientID: 1, Period: "2020-11", Accruals: 500, ClosingBalance: 500},
+			{ID: 2, ClientID: 1, Period: "2020-12", OpeningBalance: 500, Withdrawals: 100, ClosingBalance: 400},
+		},
+	}
+	h := handlers.NewHandler(tokenAuth, repo, logger.NewLogger(io.Discard))
+
+	r := chi.NewRouter()
+	r.Use(jwtauth.Verifier(tokenAuth))
+	r.Use(jwtauth.Authenticator)
+	r.Get("/api/user/statements", h.MonthlyStatements(context.Background()))
+	r.Get("/api/user/statements/{period}", h.MonthlyStatement(context.Background()))
+
+	tests := []struct {
+		name     string
+		clientID int
+		target   string
+		want     int
+		body     string
+	}{
+		{
+			name:     "case 1",
+			clientID: 1,
+			target:   "/api/user/statements",
+			want:     http.StatusOK,
+		},
+		{
+			name:     "case 2",
+			clientID: 2,
+			target:   "/api/user/statements",
+			want:     http.StatusNoContent,
+		},
+		{
+			name:     "case 3",
+			clientID: 1,
+			target:   "/api/user/statements/2020-12",
+			want:     http.StatusOK,
+			body:     `{"period":"2020-12","opening_balance":500,"accruals":0,"withdrawals":100,"closing_balance":400,"created_at":""}` + "\n",
+		},
+		{
+			name:     "case 4",
+			clientID: 2,
+			target:   "/api/user/statements/2020-12",
+			want:     http.StatusNotFound,
+		},
+		{
+			name:     "case 5",
+			clientID: 1,
+			target:   "/api/user/statements/december",
+			want:     http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newAuthRequest(t, tokenAuth, tt.clientID, http.MethodGet, tt.target, nil))
+			assert.Equal(t, tt.want, w.Code)
+
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
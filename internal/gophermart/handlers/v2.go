@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+const APIv2Prefix = "/api/v2"
+
+var ErrInvalidOrderID = errors.New("invalid order id")
+
+type orderV2Request struct {
+	Number  string  `json:"number"`
+	Amount  float64 `json:"amount"`
+	StoreID string  `json:"store_id"`
+}
+
+func (h *Handler) CreateOrderV2(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		request := orderV2Request{}
+
+		if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+			h.writeError(w, r, malformed(err))
+
+			return
+		}
+
+		order := models.Order{ClientID: clientID, Number: request.Number, Amount: request.Amount, StoreID: request.StoreID}
+
+		created, err := h.orders.Upload(ctx, &order)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		// Повторная загрузка своего заказа возвращает уже сохранённый ресурс.
+		if !created {
+			if order, err = h.orders.Find(ctx, models.Client{ID: clientID}, order.Number); err != nil {
+				h.writeError(w, r, err)
+
+				return
+			}
+
+			h.writeJSON(w, r, http.StatusOK, models.Envelope{Data: models.NewOrderResource(order)})
+
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("%s/orders/%d", APIv2Prefix, order.ID))
+		h.writeJSON(w, r, http.StatusCreated, models.Envelope{Data: models.NewOrderResource(order)})
+	}
+}
+
+func (h *Handler) OrdersV2(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		query, err := parseListQuery(r.URL.Query())
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		page, err := h.orders.OrdersPage(ctx, models.Client{ID: clientID}, query)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		orders := make([]models.OrderResource, 0, len(page.Orders))
+		for _, order := range page.Orders {
+			orders = append(orders, models.NewOrderResource(order))
+		}
+
+		h.writeJSON(w, r, http.StatusOK, pageEnvelope(r, orders, len(orders), query.Limit, page.Next))
+	}
+}
+
+func (h *Handler) OrderV2(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.writeError(w, r, ErrInvalidOrderID)
+
+			return
+		}
+
+		details, err := h.orders.OrderByID(ctx, models.Client{ID: clientID}, orderID)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		resource := models.NewOrderResource(details.Order)
+		resource.Timeline = details.Timeline
+
+		h.writeJSON(w, r, http.StatusOK, models.Envelope{Data: resource})
+	}
+}
+
+func (h *Handler) BalanceV2(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		balance, err := h.findBalance(ctx, r, clientID)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusOK, models.Envelope{Data: balance})
+	}
+}
+
+func (h *Handler) CreateWithdrawalV2(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		withdrawal := models.Withdrawal{}
+
+		if err = json.NewDecoder(r.Body).Decode(&withdrawal); err != nil {
+			h.writeError(w, r, malformed(err))
+
+			return
+		}
+
+		withdrawal.ClientID = clientID
+
+		if withdrawal, err = h.balances.Withdraw(ctx, withdrawal); err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusCreated, models.Envelope{Data: models.NewWithdrawalResource(withdrawal)})
+	}
+}
+
+func (h *Handler) WithdrawalsV2(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		clientID, err := getClientID(r)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		query, err := parseWithdrawalsQuery(r.URL.Query())
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		page, err := h.balances.WithdrawalsPage(ctx, models.Client{ID: clientID}, query)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		withdrawals := make([]models.WithdrawalResource, 0, len(page.Withdrawals))
+		for _, withdrawal := range page.Withdrawals {
+			withdrawals = append(withdrawals, models.NewWithdrawalResource(withdrawal))
+		}
+
+		h.writeJSON(w, r, http.StatusOK, pageEnvelope(r, withdrawals, len(withdrawals), query.Limit, page.Next))
+	}
+}
+
+// pageEnvelope в отличие от v1 отдаёт пустую страницу с кодом 200, а ссылку
+// на следующую страницу — в теле, а не в заголовке Link.
+func pageEnvelope(r *http.Request, data interface{}, count, limit int, next *models.Cursor) models.Envelope {
+	envelope := models.Envelope{
+		Data:  data,
+		Meta:  &models.PageMeta{Count: count, Limit: limit},
+		Links: &models.PageLinks{Self: r.URL.RequestURI()},
+	}
+
+	if next != nil {
+		envelope.Meta.NextCursor = next.Encode()
+		envelope.Links.Next = nextLink(r, next)
+	}
+
+	return envelope
+}
@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func TestOrdersV2(t *testing.T) {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	repo := &fakeRepo{
+		orders: []models.Order{
+			{ID: 1, ClientID: 1, Number: "12345678903", Status: "PROCESSED", Accrual: 50, Amount: 1000, StoreID: "store-1"},
+			{ID: 2, ClientID: 1, Number: "9278923470", Status: "NEW"},
+			{ID: 3, ClientID: 1, Number: "346436439", Status: "INVALID"},
+		},
+	}
+	h := handlers.NewHandler(tokenAuth, repo, logger.NewLogger(io.Discard))
+
+	r := chi.NewRouter()
+	r.Use(jwtauth.Verifier(tokenAuth))
+	r.Use(handlers.Authenticator)
+	r.Get("/api/v2/orders", h.OrdersV2(context.Background()))
+
+	tests := []struct {
+		name     string
+		clientID int
+		target   string
+		count    int
+		next     bool
+	}{
+		{
+			name:     "case 1",
+			clientID: 1,
+			target:   "/api/v2/orders?limit=2",
+			count:    2,
+			next:     true,
+		},
+		{
+			name:     "case 2",
+			clientID: 1,
+			target:   "/api/v2/orders",
+			count:    3,
+		},
+		{
+			name:     "case 3",
+			clientID: 2,
+			target:   "/api/v2/orders",
+			count:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newAuthRequest(t, tokenAuth, tt.clientID, http.MethodGet, tt.target, nil))
+
+			// Пустой список в v2 — это 200 с пустым data, а не 204.
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var envelope struct {
+				Data  []models.OrderResource `json:"data"`
+				Meta  models.PageMeta        `json:"meta"`
+				Links models.PageLinks       `json:"links"`
+			}
+
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&envelope))
+			assert.Len(t, envelope.Data, tt.count)
+			assert.Equal(t, tt.count, envelope.Meta.Count)
+			assert.Equal(t, tt.target, envelope.Links.Self)
+			assert.Equal(t, tt.next, envelope.Meta.NextCursor != "")
+			assert.Equal(t, tt.next, envelope.Links.Next != "")
+			assert.Empty(t, w.Header().Get("Link"))
+
+			if tt.count > 0 {
+				assert.Equal(t, 1, envelope.Data[0].ID)
+				require.NotNil(t, envelope.Data[0].AccrualRate)
+				assert.Equal(t, 0.05, *envelope.Data[0].AccrualRate)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+const (
+	defaultDeliveriesLimit = 100
+	maxDeliveriesLimit     = 1000
+)
+
+var (
+	ErrInvalidAdminToken = errors.New("invalid admin token")
+	ErrInvalidWebhookID  = errors.New("invalid webhook id")
+)
+
+func AdminAuthenticator(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+			if token == "" || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+				writeProblem(w, r, NewProblem(ErrInvalidAdminToken))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (h *Handler) CreateWebhook(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		subscription := models.WebhookSubscription{}
+
+		if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+			h.writeError(w, r, malformed(err))
+
+			return
+		}
+
+		subscription, err := h.webhooks.Subscribe(ctx, subscription)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusCreated, subscription)
+	}
+}
+
+func (h *Handler) Webhooks(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		subscriptions, err := h.webhooks.Subscriptions(ctx)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		if len(subscriptions) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusOK, subscriptions)
+	}
+}
+
+func (h *Handler) DeleteWebhook(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		subscriptionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.writeError(w, r, ErrInvalidWebhookID)
+
+			return
+		}
+
+		if err = h.webhooks.Unsubscribe(ctx, subscriptionID); err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		fmt.Fprintf(w, "{}")
+	}
+}
+
+func (h *Handler) WebhookDeliveries(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		subscriptionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.writeError(w, r, ErrInvalidWebhookID)
+
+			return
+		}
+
+		limit := defaultDeliveriesLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 || limit > maxDeliveriesLimit {
+				h.writeError(w, r, models.ErrInvalidPageLimit)
+
+				return
+			}
+		}
+
+		deliveries, err := h.webhooks.Deliveries(ctx, subscriptionID, limit)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		if len(deliveries) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusOK, deliveries)
+	}
+}
+
+func (h *Handler) ReplayWebhook(ctx context.Context) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		subscriptionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.writeError(w, r, ErrInvalidWebhookID)
+
+			return
+		}
+
+		var replay struct {
+			FromEventID int64 `json:"from_event_id"`
+		}
+
+		if err = json.NewDecoder(r.Body).Decode(&replay); err != nil {
+			h.writeError(w, r, malformed(err))
+
+			return
+		}
+
+		count, err := h.webhooks.Replay(ctx, subscriptionID, replay.FromEventID)
+		if err != nil {
+			h.writeError(w, r, err)
+
+			return
+		}
+
+		h.writeJSON(w, r, http.StatusAccepted, map[string]int{"queued": count})
+	}
+}
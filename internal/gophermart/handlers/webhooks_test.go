@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/handlers"
+)
+
+func TestAdminAuthenticator(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          int
+	}{
+		{
+			name:          "case 1",
+			token:         "admin",
+			authorization: "Bearer admin",
+			want:          http.StatusOK,
+		},
+		{
+			name:          "case 2",
+			token:         "admin",
+			authorization: "Bearer user",
+			want:          http.StatusUnauthorized,
+		},
+		{
+			name:          "case 3",
+			token:         "admin",
+			authorization: "",
+			want:          http.StatusUnauthorized,
+		},
+		{
+			name:          "case 4",
+			token:         "",
+			authorization: "Bearer ",
+			want:          http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/webhooks", nil)
+			req.Header.Set("Authorization", tt.authorization)
+			w := httptest.NewRecorder()
+
+			handlers.AdminAuthenticator(tt.token)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,2 @@
+alter table orders drop column if exists "store_id";
+alter table orders drop column if exists "amount";
@@ -0,0 +1,2 @@
+alter table orders add column "amount" double precision;
+alter table orders add column "store_id" varchar(64);
@@ -0,0 +1 @@
+create index "orders_status_idx" ON orders ("status");
@@ -0,0 +1 @@
+drop table order_history cascade;
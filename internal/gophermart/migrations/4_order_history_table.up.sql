@@ -0,0 +1,13 @@
+create table order_history (
+    "history_id"    serial primary key,
+    "order_id"      int not null references orders on delete cascade,
+    "status_from"   order_status,
+    "status_to"     order_status not null,
+    "accrual"       double precision default 0,
+    "changed_at"    timestamp with time zone not null default now()
+);
+
+create index "order_history_order_id_idx" ON order_history ("order_id");
+
+insert into order_history (order_id, status_from, status_to, accrual, changed_at)
+    select order_id, null, status, accrual, uploaded_at from orders where status is not null;
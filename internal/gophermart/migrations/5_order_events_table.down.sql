@@ -0,0 +1,6 @@
+alter table order_events drop column "event";
+
+alter index "order_events_order_id_idx" rename to "order_history_order_id_idx";
+alter table order_events rename column "created_at" to "changed_at";
+alter table order_events rename column "event_id" to "history_id";
+alter table order_events rename to order_history;
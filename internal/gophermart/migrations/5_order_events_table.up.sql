@@ -0,0 +1,12 @@
+alter table order_history rename to order_events;
+alter table order_events rename column "history_id" to "event_id";
+alter table order_events rename column "changed_at" to "created_at";
+alter index "order_history_order_id_idx" rename to "order_events_order_id_idx";
+
+alter table order_events add column "event" varchar(16) not null default 'ACCRUAL';
+
+update order_events set "event" = 'UPLOADED' where status_from is null;
+update order_events set "event" = 'PICKED_UP' where status_from = 'NEW' and status_to = 'PROCESSING';
+update order_events set "event" = 'FINAL' where status_from is not null and status_to in ('INVALID', 'PROCESSED');
+
+alter table order_events alter column "event" drop default;
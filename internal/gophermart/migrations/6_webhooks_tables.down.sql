@@ -0,0 +1,3 @@
+drop table webhook_deliveries cascade;
+drop table webhook_subscriptions cascade;
+drop table outbox cascade;
@@ -0,0 +1,34 @@
+create table outbox (
+    "event_id"                  bigserial primary key,
+    "client_id"                 int not null references clients on delete cascade,
+    "event_type"                varchar(64) not null,
+    "payload"                   jsonb not null,
+    "created_at"                timestamp with time zone not null default now(),
+    "webhooks_dispatched_at"    timestamp with time zone
+);
+
+create index "outbox_webhooks_pending_idx" ON outbox ("event_id") where "webhooks_dispatched_at" is null;
+
+create table webhook_subscriptions (
+    "subscription_id"   serial primary key,
+    "url"               character varying not null,
+    "secret"            character varying not null,
+    "event_types"       text[] not null default '{}',
+    "created_at"        timestamp with time zone not null default now()
+);
+
+create table webhook_deliveries (
+    "delivery_id"       bigserial primary key,
+    "subscription_id"   int not null references webhook_subscriptions on delete cascade,
+    "event_id"          bigint not null references outbox on delete cascade,
+    "state"             varchar(16) not null default 'PENDING',
+    "attempts"          int not null default 0,
+    "last_status_code"  int,
+    "last_error"        character varying,
+    "next_attempt_at"   timestamp with time zone not null default now(),
+    "delivered_at"      timestamp with time zone,
+    "created_at"        timestamp with time zone not null default now()
+);
+
+create index "webhook_deliveries_due_idx" ON webhook_deliveries ("next_attempt_at") where "state" = 'PENDING';
+create index "webhook_deliveries_subscription_id_idx" ON webhook_deliveries ("subscription_id", "delivery_id");
@@ -0,0 +1,8 @@
+alter table outbox add column "webhooks_dispatched_at" timestamp with time zone;
+
+update outbox set webhooks_dispatched_at = now()
+    where event_id <= (select event_id from outbox_cursors where sink = 'webhook');
+
+create index "outbox_webhooks_pending_idx" ON outbox ("event_id") where "webhooks_dispatched_at" is null;
+
+drop table outbox_cursors cascade;
@@ -0,0 +1,11 @@
+create table outbox_cursors (
+    "sink"          varchar(64) primary key,
+    "event_id"      bigint not null default 0,
+    "updated_at"    timestamp with time zone not null default now()
+);
+
+insert into outbox_cursors (sink, event_id)
+    select 'webhook', COALESCE(max(event_id), 0) from outbox where webhooks_dispatched_at is not null;
+
+drop index "outbox_webhooks_pending_idx";
+alter table outbox drop column "webhooks_dispatched_at";
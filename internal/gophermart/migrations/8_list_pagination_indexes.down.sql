@@ -0,0 +1,3 @@
+drop index if exists "withdrawals_client_processed_idx";
+
+drop index if exists "orders_client_uploaded_idx";
@@ -0,0 +1,3 @@
+create index "orders_client_uploaded_idx" ON orders ("client_id", "uploaded_at", "order_id");
+
+create index "withdrawals_client_processed_idx" ON withdrawals ("client_id", "processed_at", "withdrawal_id");
@@ -0,0 +1,14 @@
+create table statements (
+    "statement_id"      serial primary key,
+    "client_id"         int not null references clients on delete cascade,
+    "period"            date not null,
+    "opening_balance"   double precision not null default 0,
+    "accruals"          double precision not null default 0,
+    "withdrawals"       double precision not null default 0,
+    "closing_balance"   double precision not null default 0,
+    "created_at"        timestamp with time zone not null default now(),
+    "notified_at"       timestamp with time zone,
+    unique ("client_id", "period")
+);
+
+create index "statements_pending_idx" ON statements ("statement_id") where "notified_at" is null;
@@ -0,0 +1,14 @@
+// Package migrations встраивает миграции в бинарный файл, чтобы сервис
+// не зависел от рабочего каталога.
+package migrations
+
+import "embed"
+
+// Каталоги миграций внутри FS.
+const (
+	PostgreSQL = "."
+	SQLite     = "sqlite"
+)
+
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,56 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/migrations"
+)
+
+func TestMigrations(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		last uint
+	}{
+		{
+			name: "case 1",
+			dir:  migrations.PostgreSQL,
+			last: 11,
+		},
+		{
+			name: "case 2",
+			dir:  migrations.SQLite,
+			last: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := iofs.New(migrations.FS, tt.dir)
+			require.NoError(t, err)
+
+			defer source.Close()
+
+			version, err := source.First()
+			require.NoError(t, err)
+			assert.Equal(t, uint(1), version)
+
+			for {
+				next, err := source.Next(version)
+				if err != nil {
+					break
+				}
+
+				_, _, err = source.ReadUp(next)
+				assert.NoError(t, err)
+
+				version = next
+			}
+
+			assert.Equal(t, tt.last, version)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+drop table statements;
+drop table webhook_deliveries;
+drop table webhook_subscriptions;
+drop table outbox_cursors;
+drop table outbox;
+drop table order_events;
+drop table withdrawals;
+drop table orders;
+drop table clients;
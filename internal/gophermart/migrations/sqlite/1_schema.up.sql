@@ -0,0 +1,88 @@
+create table clients (
+    "client_id" integer primary key autoincrement,
+    "login"     text not null unique,
+    "password"  text not null
+);
+
+create table orders (
+    "order_id"      integer primary key autoincrement,
+    "client_id"     integer not null references clients on delete cascade,
+    "order_number"  text not null unique,
+    "accrual"       real not null default 0,
+    "status"        text not null check ("status" in ('NEW', 'REGISTERED', 'PROCESSING', 'INVALID', 'PROCESSED')),
+    "uploaded_at"   text not null,
+    "amount"        real,
+    "store_id"      text
+);
+create index "orders_client_uploaded_idx" ON orders ("client_id", "uploaded_at", "order_id");
+create index "orders_status_idx" ON orders ("status");
+
+create table withdrawals (
+    "withdrawal_id" integer primary key autoincrement,
+    "client_id"     integer not null references clients on delete cascade,
+    "order_number"  text not null,
+    "sum"           real not null default 0,
+    "processed_at"  text not null
+);
+create index "withdrawals_client_processed_idx" ON withdrawals ("client_id", "processed_at", "withdrawal_id");
+
+create table order_events (
+    "event_id"      integer primary key autoincrement,
+    "order_id"      integer not null references orders on delete cascade,
+    "event"         text not null,
+    "status_from"   text,
+    "status_to"     text not null,
+    "accrual"       real default 0,
+    "created_at"    text not null
+);
+create index "order_events_order_id_idx" ON order_events ("order_id");
+
+create table outbox (
+    "event_id"      integer primary key autoincrement,
+    "client_id"     integer not null references clients on delete cascade,
+    "event_type"    text not null,
+    "payload"       text not null,
+    "created_at"    text not null
+);
+
+create table outbox_cursors (
+    "sink"          text primary key,
+    "event_id"      integer not null default 0,
+    "updated_at"    text not null
+);
+
+create table webhook_subscriptions (
+    "subscription_id"   integer primary key autoincrement,
+    "url"               text not null,
+    "secret"            text not null,
+    "event_types"       text not null default '[]',
+    "created_at"        text not null
+);
+
+create table webhook_deliveries (
+    "delivery_id"       integer primary key autoincrement,
+    "subscription_id"   integer not null references webhook_subscriptions on delete cascade,
+    "event_id"          integer not null references outbox on delete cascade,
+    "state"             text not null default 'PENDING',
+    "attempts"          integer not null default 0,
+    "last_status_code"  integer,
+    "last_error"        text,
+    "next_attempt_at"   text not null,
+    "delivered_at"      text,
+    "created_at"        text not null
+);
+create index "webhook_deliveries_due_idx" ON webhook_deliveries ("state", "next_attempt_at");
+create index "webhook_deliveries_subscription_id_idx" ON webhook_deliveries ("subscription_id", "delivery_id");
+
+create table statements (
+    "statement_id"      integer primary key autoincrement,
+    "client_id"         integer not null references clients on delete cascade,
+    "period"            text not null,
+    "opening_balance"   real not null default 0,
+    "accruals"          real not null default 0,
+    "withdrawals"       real not null default 0,
+    "closing_balance"   real not null default 0,
+    "created_at"        text not null,
+    "notified_at"       text,
+    unique ("client_id", "period")
+);
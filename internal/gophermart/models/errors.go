@@ -7,4 +7,17 @@ var (
 	ErrLongLogin                = errors.New("login length is more than 64 characters")
 	ErrInvalidOrderNumberFormat = errors.New("invalid order number format")
 	ErrWrongWithdrawalSum       = errors.New("withdrawal sum must be greater than zero")
+	ErrUnknownOrderStatus       = errors.New("unknown order status")
+	ErrIllegalStatusTransition  = errors.New("illegal order status transition")
+	ErrInvalidWebhookURL        = errors.New("webhook url must be an absolute http(s) url")
+	ErrEmptyWebhookSecret       = errors.New("webhook secret is empty")
+	ErrUnknownEventType         = errors.New("unknown event type")
+	ErrInvalidCursor            = errors.New("invalid page cursor")
+	ErrInvalidPageLimit         = errors.New("page limit must be between 1 and 1000")
+	ErrInvalidDateRange         = errors.New("invalid date range")
+	ErrInvalidMinAccrual        = errors.New("min accrual must not be negative")
+	ErrInvalidStatementPeriod   = errors.New("statement period must be in YYYY-MM format")
+	ErrInvalidBalanceTime       = errors.New("balance time must be in RFC3339 format")
+	ErrInvalidOrderAmount       = errors.New("order amount must not be negative")
+	ErrLongStoreID              = errors.New("store id length is more than 64 characters")
 )
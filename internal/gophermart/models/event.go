@@ -0,0 +1,29 @@
+package models
+
+const (
+	OrderEventUploaded = "UPLOADED"
+	OrderEventPickedUp = "PICKED_UP"
+	OrderEventAccrual  = "ACCRUAL"
+	OrderEventFinal    = "FINAL"
+)
+
+type OrderEvent struct {
+	Event      string      `json:"event"`
+	StatusFrom OrderStatus `json:"status_from,omitempty"`
+	Status     OrderStatus `json:"status"`
+	Accrual    float64     `json:"accrual,omitempty"`
+	CreatedAt  string      `json:"created_at"`
+}
+
+func OrderEventForAccrual(status OrderStatus) string {
+	if IsFinalStatus(status) {
+		return OrderEventFinal
+	}
+
+	return OrderEventAccrual
+}
+
+type OrderDetails struct {
+	Order
+	Timeline []OrderEvent `json:"timeline"`
+}
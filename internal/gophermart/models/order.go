@@ -6,13 +6,26 @@ import (
 	"github.com/vukit/gomac/internal/gophermart/utils"
 )
 
+const (
+	OrderUploadAccepted        = "accepted"
+	OrderUploadAlreadyUploaded = "already_uploaded"
+	OrderUploadConflict        = "conflict"
+	OrderUploadInvalid         = "invalid"
+)
+
+const maxStoreIDLength = 64
+
 type Order struct {
-	ID         int     `json:"-"`
-	ClientID   int     `json:"-"`
-	Number     string  `json:"number"`
-	Status     string  `json:"status"`
-	Accrual    float64 `json:"accrual,omitempty"`
-	UploadedAt string  `json:"uploaded_at"`
+	ID         int         `json:"-"`
+	ClientID   int         `json:"-"`
+	Number     string      `json:"number"`
+	Status     OrderStatus `json:"status"`
+	Accrual    float64     `json:"accrual,omitempty"`
+	UploadedAt string      `json:"uploaded_at"`
+
+	// Поля API v2, в ответах v1 не отдаются.
+	Amount  float64 `json:"-"`
+	StoreID string  `json:"-"`
 }
 
 func (r *Order) Validate() error {
@@ -21,5 +34,27 @@ func (r *Order) Validate() error {
 		return ErrInvalidOrderNumberFormat
 	}
 
+	if r.Amount < 0 {
+		return ErrInvalidOrderAmount
+	}
+
+	if len(r.StoreID) > maxStoreIDLength {
+		return ErrLongStoreID
+	}
+
 	return nil
 }
+
+// AccrualRate — доля начисления от суммы заказа; без суммы не определена.
+func (r *Order) AccrualRate() (float64, bool) {
+	if r.Amount <= 0 {
+		return 0, false
+	}
+
+	return r.Accrual / r.Amount, true
+}
+
+type OrderUploadResult struct {
+	Number string `json:"number"`
+	Result string `json:"result"`
+}
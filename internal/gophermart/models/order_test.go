@@ -1,6 +1,8 @@
 package models_test
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +38,70 @@ func TestOrder(t *testing.T) {
 	}
 
 }
+
+func TestOrderMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		storeID string
+		want    error
+	}{
+		{
+			name:    "case 1",
+			amount:  1000,
+			storeID: "store-1",
+			want:    nil,
+		},
+		{
+			name:   "case 2",
+			amount: -1,
+			want:   models.ErrInvalidOrderAmount,
+		},
+		{
+			name:    "case 3",
+			storeID: strings.Repeat("s", 65),
+			want:    models.ErrLongStoreID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := models.Order{Number: "12345678903", Amount: tt.amount, StoreID: tt.storeID}
+			assert.Equal(t, tt.want, order.Validate())
+		})
+	}
+}
+
+func TestOrderResource(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    models.Order
+		wantRate interface{}
+	}{
+		{
+			name:     "case 1",
+			order:    models.Order{ID: 1, Number: "12345678903", Accrual: 50, Amount: 1000, StoreID: "store-1"},
+			wantRate: 0.05,
+		},
+		{
+			name:     "case 2",
+			order:    models.Order{ID: 2, Number: "9278923470", Accrual: 50},
+			wantRate: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(models.NewOrderResource(tt.order))
+			assert.NoError(t, err)
+
+			resource := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(data, &resource))
+
+			assert.Equal(t, float64(tt.order.ID), resource["id"])
+			assert.Equal(t, tt.wantRate, resource["accrual_rate"])
+			assert.Contains(t, resource, "amount")
+			assert.Contains(t, resource, "store_id")
+		})
+	}
+}
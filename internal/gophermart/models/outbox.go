@@ -0,0 +1,70 @@
+package models
+
+import "encoding/json"
+
+const (
+	EventOrderUploaded      = "order.uploaded"
+	EventOrderStatusChanged = "order.status_changed"
+	EventPointsCredited     = "points.credited"
+	EventPointsWithdrawn    = "points.withdrawn"
+	EventStatementGenerated = "statement.generated"
+)
+
+var eventTypes = map[string]bool{
+	EventOrderUploaded:      true,
+	EventOrderStatusChanged: true,
+	EventPointsCredited:     true,
+	EventPointsWithdrawn:    true,
+	EventStatementGenerated: true,
+}
+
+type OutboxEvent struct {
+	ID        int64  `json:"id"`
+	ClientID  int    `json:"client_id"`
+	Type      string `json:"type"`
+	Payload   []byte `json:"-"`
+	CreatedAt string `json:"created_at"`
+}
+
+type OrderUploaded struct {
+	Number string `json:"number"`
+}
+
+type OrderStatusChanged struct {
+	Number     string      `json:"number"`
+	StatusFrom OrderStatus `json:"status_from"`
+	Status     OrderStatus `json:"status"`
+	Accrual    float64     `json:"accrual,omitempty"`
+}
+
+type PointsCredited struct {
+	Order   string  `json:"order"`
+	Accrual float64 `json:"accrual"`
+}
+
+type PointsWithdrawn struct {
+	Order string  `json:"order"`
+	Sum   float64 `json:"sum"`
+}
+
+type StatementGenerated struct {
+	Period         string  `json:"period"`
+	OpeningBalance float64 `json:"opening_balance"`
+	ClosingBalance float64 `json:"closing_balance"`
+}
+
+func MarshalOutboxEvent(event OutboxEvent) ([]byte, error) {
+	return json.Marshal(struct {
+		ID        int64           `json:"id"`
+		Type      string          `json:"type"`
+		ClientID  int             `json:"client_id"`
+		CreatedAt string          `json:"created_at"`
+		Data      json.RawMessage `json:"data"`
+	}{
+		ID:        event.ID,
+		Type:      event.Type,
+		ClientID:  event.ClientID,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 1000
+)
+
+// Cursor указывает на последнюю запись предыдущей страницы:
+// страницы упорядочены по времени, а при равном времени — по идентификатору.
+type Cursor struct {
+	At time.Time
+	ID int
+}
+
+func (r Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(r.At.Format(time.RFC3339Nano) + "|" + strconv.Itoa(r.ID)))
+}
+
+func DecodeCursor(value string) (cursor Cursor, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(data), "|", 2)
+	if len(parts) != 2 {
+		return cursor, ErrInvalidCursor
+	}
+
+	if cursor.At, err = time.Parse(time.RFC3339Nano, parts[0]); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	if cursor.ID, err = strconv.Atoi(parts[1]); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+type ListQuery struct {
+	Limit      int
+	After      *Cursor
+	Statuses   []OrderStatus
+	From       time.Time
+	To         time.Time
+	MinAccrual float64
+	Desc       bool
+}
+
+func (r *ListQuery) Validate() error {
+	if r.Limit <= 0 || r.Limit > MaxPageLimit {
+		return ErrInvalidPageLimit
+	}
+
+	for _, status := range r.Statuses {
+		if !status.Valid() {
+			return ErrUnknownOrderStatus
+		}
+	}
+
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		return ErrInvalidDateRange
+	}
+
+	if r.MinAccrual < 0 {
+		return ErrInvalidMinAccrual
+	}
+
+	return nil
+}
+
+type OrdersPage struct {
+	Orders []Order
+	Next   *Cursor
+}
+
+type WithdrawalsPage struct {
+	Withdrawals []Withdrawal
+	Next        *Cursor
+}
@@ -0,0 +1,71 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := models.Cursor{At: time.Date(2020, 12, 10, 15, 15, 45, 123000, time.FixedZone("", 3*60*60)), ID: 42}
+
+	decoded, err := models.DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.At.Equal(decoded.At))
+	assert.Equal(t, cursor.ID, decoded.ID)
+
+	for _, value := range []string{"", "!!!", "MjAyMA", "bm90LWEtZGF0ZXwx"} {
+		_, err = models.DecodeCursor(value)
+		assert.Equal(t, models.ErrInvalidCursor, err, value)
+	}
+}
+
+func TestListQuery(t *testing.T) {
+	from := time.Date(2020, 12, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query models.ListQuery
+		want  error
+	}{
+		{
+			name:  "case 1",
+			query: models.ListQuery{Limit: 10, Statuses: []models.OrderStatus{"NEW", "PROCESSED"}, From: from, To: from.AddDate(0, 0, 1), MinAccrual: 100},
+			want:  nil,
+		},
+		{
+			name:  "case 2",
+			query: models.ListQuery{Limit: 0},
+			want:  models.ErrInvalidPageLimit,
+		},
+		{
+			name:  "case 3",
+			query: models.ListQuery{Limit: models.MaxPageLimit + 1},
+			want:  models.ErrInvalidPageLimit,
+		},
+		{
+			name:  "case 4",
+			query: models.ListQuery{Limit: 10, Statuses: []models.OrderStatus{"DONE"}},
+			want:  models.ErrUnknownOrderStatus,
+		},
+		{
+			name:  "case 5",
+			query: models.ListQuery{Limit: 10, From: from, To: from.AddDate(0, 0, -1)},
+			want:  models.ErrInvalidDateRange,
+		},
+		{
+			name:  "case 6",
+			query: models.ListQuery{Limit: 10, MinAccrual: -1},
+			want:  models.ErrInvalidMinAccrual,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.query.Validate())
+		})
+	}
+}
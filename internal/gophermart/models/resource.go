@@ -0,0 +1,76 @@
+package models
+
+// Ресурсы API v2: в отличие от v1 у каждого есть идентификатор, а
+// отсутствующие значения передаются как null, а не опускаются.
+
+type OrderResource struct {
+	ID          int          `json:"id"`
+	Number      string       `json:"number"`
+	Status      OrderStatus  `json:"status"`
+	Accrual     float64      `json:"accrual"`
+	Amount      *float64     `json:"amount"`
+	StoreID     *string      `json:"store_id"`
+	AccrualRate *float64     `json:"accrual_rate"`
+	UploadedAt  string       `json:"uploaded_at"`
+	Timeline    []OrderEvent `json:"timeline,omitempty"`
+}
+
+func NewOrderResource(order Order) OrderResource {
+	resource := OrderResource{
+		ID:         order.ID,
+		Number:     order.Number,
+		Status:     order.Status,
+		Accrual:    order.Accrual,
+		UploadedAt: order.UploadedAt,
+	}
+
+	if order.Amount > 0 {
+		amount := order.Amount
+		resource.Amount = &amount
+	}
+
+	if order.StoreID != "" {
+		storeID := order.StoreID
+		resource.StoreID = &storeID
+	}
+
+	if rate, ok := order.AccrualRate(); ok {
+		resource.AccrualRate = &rate
+	}
+
+	return resource
+}
+
+type WithdrawalResource struct {
+	ID          int     `json:"id"`
+	Order       string  `json:"order"`
+	Sum         float64 `json:"sum"`
+	ProcessedAt string  `json:"processed_at"`
+}
+
+func NewWithdrawalResource(withdrawal Withdrawal) WithdrawalResource {
+	return WithdrawalResource{
+		ID:          withdrawal.ID,
+		Order:       withdrawal.Order,
+		Sum:         withdrawal.Sum,
+		ProcessedAt: withdrawal.ProcessedAt,
+	}
+}
+
+// Envelope — общий конверт ответов v2; Meta и Links есть только у списков.
+type Envelope struct {
+	Data  interface{} `json:"data"`
+	Meta  *PageMeta   `json:"meta,omitempty"`
+	Links *PageLinks  `json:"links,omitempty"`
+}
+
+type PageMeta struct {
+	Count      int    `json:"count"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type PageLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	StatementAccrual    = "accrual"
+	StatementWithdrawal = "withdrawal"
+
+	StatementPeriodLayout = "2006-01"
+)
+
+// StatementEntry — строка выписки: начисления положительны, списания
+// отрицательны, Balance — баланс клиента после операции.
+type StatementEntry struct {
+	At      string  `json:"at"`
+	Type    string  `json:"type"`
+	Order   string  `json:"order"`
+	Amount  float64 `json:"amount"`
+	Balance float64 `json:"balance"`
+}
+
+type MonthlyStatement struct {
+	ID             int     `json:"-"`
+	ClientID       int     `json:"-"`
+	Period         string  `json:"period"`
+	OpeningBalance float64 `json:"opening_balance"`
+	Accruals       float64 `json:"accruals"`
+	Withdrawals    float64 `json:"withdrawals"`
+	ClosingBalance float64 `json:"closing_balance"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// StatementPeriod возвращает первый день последнего завершённого месяца.
+func StatementPeriod(now time.Time) time.Time {
+	now = now.UTC()
+
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+}
+
+func ParseStatementPeriod(value string) (time.Time, error) {
+	period, err := time.Parse(StatementPeriodLayout, value)
+	if err != nil {
+		return period, ErrInvalidStatementPeriod
+	}
+
+	return period, nil
+}
@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func TestStatementPeriod(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{
+			name: "case 1",
+			now:  time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: "2020-12",
+		},
+		{
+			name: "case 2",
+			now:  time.Date(2021, 3, 31, 23, 59, 0, 0, time.UTC),
+			want: "2021-02",
+		},
+		{
+			name: "case 3",
+			now:  time.Date(2021, 3, 1, 2, 0, 0, 0, time.FixedZone("", 3*60*60)),
+			want: "2021-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, models.StatementPeriod(tt.now).Format(models.StatementPeriodLayout))
+		})
+	}
+}
+
+func TestParseStatementPeriod(t *testing.T) {
+	period, err := models.ParseStatementPeriod("2020-12")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), period)
+
+	for _, value := range []string{"", "2020-13", "2020-12-01", "december"} {
+		_, err = models.ParseStatementPeriod(value)
+		assert.Equal(t, models.ErrInvalidStatementPeriod, err, value)
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import "fmt"
+
+// OrderStatus — статус заказа; в базе хранится его строковое значение.
+type OrderStatus string
+
+const (
+	StatusNew        OrderStatus = "NEW"
+	StatusRegistered OrderStatus = "REGISTERED"
+	StatusProcessing OrderStatus = "PROCESSING"
+	StatusInvalid    OrderStatus = "INVALID"
+	StatusProcessed  OrderStatus = "PROCESSED"
+)
+
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	"":               {StatusNew},
+	StatusNew:        {StatusRegistered, StatusProcessing, StatusInvalid, StatusProcessed},
+	StatusRegistered: {StatusRegistered, StatusProcessing, StatusInvalid, StatusProcessed},
+	StatusProcessing: {StatusProcessing, StatusInvalid, StatusProcessed},
+	StatusInvalid:    {},
+	StatusProcessed:  {},
+}
+
+func (s OrderStatus) Valid() bool {
+	_, ok := orderTransitions[s]
+
+	return ok && s != ""
+}
+
+func IsFinalStatus(status OrderStatus) bool {
+	return status == StatusInvalid || status == StatusProcessed
+}
+
+// StatusFromAccrual переводит статус системы расчёта начислений в статус заказа:
+// REGISTERED для клиента означает, что вознаграждение ещё рассчитывается.
+func StatusFromAccrual(status string) (OrderStatus, error) {
+	switch OrderStatus(status) {
+	case StatusRegistered, StatusProcessing:
+		return StatusProcessing, nil
+	case StatusInvalid, StatusProcessed:
+		return OrderStatus(status), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownOrderStatus, status)
+	}
+}
+
+func ValidateTransition(from, to OrderStatus) error {
+	if !to.Valid() {
+		return fmt.Errorf("%w: %q", ErrUnknownOrderStatus, to)
+	}
+
+	for _, status := range orderTransitions[from] {
+		if status == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s -> %s", ErrIllegalStatusTransition, from, to)
+}
@@ -0,0 +1,97 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		name string
+		from models.OrderStatus
+		to   models.OrderStatus
+		want error
+	}{
+		{
+			name: "case 1",
+			from: "",
+			to:   models.StatusNew,
+			want: nil,
+		},
+		{
+			name: "case 2",
+			from: models.StatusNew,
+			to:   models.StatusProcessing,
+			want: nil,
+		},
+		{
+			name: "case 3",
+			from: models.StatusProcessing,
+			to:   models.StatusProcessing,
+			want: nil,
+		},
+		{
+			name: "case 4",
+			from: models.StatusProcessing,
+			to:   models.StatusProcessed,
+			want: nil,
+		},
+		{
+			name: "case 5",
+			from: models.StatusProcessed,
+			to:   models.StatusProcessing,
+			want: models.ErrIllegalStatusTransition,
+		},
+		{
+			name: "case 6",
+			from: models.StatusInvalid,
+			to:   models.StatusProcessed,
+			want: models.ErrIllegalStatusTransition,
+		},
+		{
+			name: "case 7",
+			from: models.StatusProcessing,
+			to:   models.StatusNew,
+			want: models.ErrIllegalStatusTransition,
+		},
+		{
+			name: "case 8",
+			from: models.StatusNew,
+			to:   "DONE",
+			want: models.ErrUnknownOrderStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.ValidateTransition(tt.from, tt.to)
+			assert.True(t, errors.Is(err, tt.want), err)
+		})
+	}
+}
+
+func TestStatusFromAccrual(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		want    models.OrderStatus
+		wantErr error
+	}{
+		{name: "case 1", status: "REGISTERED", want: models.StatusProcessing},
+		{name: "case 2", status: "PROCESSING", want: models.StatusProcessing},
+		{name: "case 3", status: "INVALID", want: models.StatusInvalid},
+		{name: "case 4", status: "PROCESSED", want: models.StatusProcessed},
+		{name: "case 5", status: "NEW", wantErr: models.ErrUnknownOrderStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := models.StatusFromAccrual(tt.status)
+			assert.True(t, errors.Is(err, tt.wantErr), err)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
@@ -2,7 +2,8 @@ package models
 
 type Task struct {
 	OrderID     int
+	ClientID    int
 	OrderNumber string
 	Accrual     float64
-	Status      string
+	Status      OrderStatus
 }
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryFailed    = "FAILED"
+)
+
+type WebhookSubscription struct {
+	ID         int      `json:"id"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+func (r *WebhookSubscription) Validate() error {
+	endpoint, err := url.Parse(r.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	if strings.TrimSpace(r.Secret) == "" {
+		return ErrEmptyWebhookSecret
+	}
+
+	for _, eventType := range r.EventTypes {
+		if !eventTypes[eventType] {
+			return ErrUnknownEventType
+		}
+	}
+
+	return nil
+}
+
+// Accepts возвращает true, если подписка получает события данного типа;
+// пустой список типов означает подписку на все события.
+func (r *WebhookSubscription) Accepts(eventType string) bool {
+	if len(r.EventTypes) == 0 {
+		return true
+	}
+
+	for _, accepted := range r.EventTypes {
+		if accepted == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type WebhookDelivery struct {
+	ID             int64  `json:"id"`
+	SubscriptionID int    `json:"subscription_id"`
+	EventID        int64  `json:"event_id"`
+	EventType      string `json:"event_type"`
+	State          string `json:"state"`
+	Attempts       int    `json:"attempts"`
+	LastStatusCode int    `json:"last_status_code,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
+	NextAttemptAt  string `json:"next_attempt_at,omitempty"`
+	DeliveredAt    string `json:"delivered_at,omitempty"`
+	CreatedAt      string `json:"created_at"`
+
+	URL     string `json:"-"`
+	Secret  string `json:"-"`
+	Payload []byte `json:"-"`
+}
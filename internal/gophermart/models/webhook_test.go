@@ -0,0 +1,59 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func TestWebhookSubscription(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		secret     string
+		eventTypes []string
+		want       error
+	}{
+		{
+			name:       "case 1",
+			url:        "https://crm.example.com/hooks",
+			secret:     "secret",
+			eventTypes: []string{models.EventPointsCredited, models.EventPointsWithdrawn},
+			want:       nil,
+		},
+		{
+			name:   "case 2",
+			url:    "crm.example.com/hooks",
+			secret: "secret",
+			want:   models.ErrInvalidWebhookURL,
+		},
+		{
+			name:   "case 3",
+			url:    "https://crm.example.com/hooks",
+			secret: " ",
+			want:   models.ErrEmptyWebhookSecret,
+		},
+		{
+			name:       "case 4",
+			url:        "https://crm.example.com/hooks",
+			secret:     "secret",
+			eventTypes: []string{"points.expired"},
+			want:       models.ErrUnknownEventType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := models.WebhookSubscription{URL: tt.url, Secret: tt.secret, EventTypes: tt.eventTypes}
+			assert.Equal(t, tt.want, subscription.Validate())
+		})
+	}
+
+	all := models.WebhookSubscription{}
+	assert.True(t, all.Accepts(models.EventOrderStatusChanged))
+
+	credited := models.WebhookSubscription{EventTypes: []string{models.EventPointsCredited}}
+	assert.True(t, credited.Accepts(models.EventPointsCredited))
+	assert.False(t, credited.Accepts(models.EventPointsWithdrawn))
+}
@@ -0,0 +1,44 @@
+package openapi
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+)
+
+const (
+	SpecPath = "/api/openapi.json"
+	DocsPath = "/api/docs"
+)
+
+//go:embed openapi.json
+var Spec []byte
+
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	_, _ = w.Write(Spec)
+}
+
+// DocsHandler отдаёт страницу Swagger UI; сам UI загружается с CDN,
+// спецификация — с этого же сервиса.
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	fmt.Fprintf(w, `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Gophermart API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+  window.ui = SwaggerUIBundle({url: %q, dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`, SpecPath)
+}
@@ -0,0 +1,625 @@
+package openapi_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/config"
+	"github.com/vukit/gomac/internal/gophermart/events"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/openapi"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/router"
+	"github.com/vukit/gomac/internal/gophermart/utils"
+)
+
+const (
+	adminToken    = "admin-token"
+	accrualSecret = "accrual-secret"
+)
+
+type object = map[string]interface{}
+
+type spec struct {
+	doc object
+}
+
+func loadSpec(t *testing.T) spec {
+	t.Helper()
+
+	doc := object{}
+	require.NoError(t, json.Unmarshal(openapi.Spec, &doc))
+
+	return spec{doc: doc}
+}
+
+func (r spec) paths() object {
+	return r.doc["paths"].(object)
+}
+
+func (r spec) resolve(schema object) object {
+	for {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+
+		node := interface{}(r.doc)
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			node = node.(object)[part]
+		}
+
+		schema = node.(object)
+	}
+}
+
+// validate проверяет значение по подмножеству JSON Schema, которое
+// используется в спецификации: $ref, allOf, type, required, properties,
+// items, enum, nullable и format date-time.
+func (r spec) validate(schema object, value interface{}, path string) error {
+	schema = r.resolve(schema)
+
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		schema = r.merge(allOf)
+	}
+
+	if value == nil && schema["nullable"] == true {
+		return nil
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+
+		for _, allowed := range enum {
+			if allowed == value {
+				found = true
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		fields, ok := value.(object)
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, value)
+		}
+
+		for _, name := range toStrings(schema["required"]) {
+			if _, ok := fields[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+
+		properties, _ := schema["properties"].(object)
+
+		for name, field := range fields {
+			property, ok := properties[name]
+			if !ok {
+				if len(properties) > 0 {
+					return fmt.Errorf("%s: undocumented property %q", path, name)
+				}
+
+				continue
+			}
+
+			if err := r.validate(property.(object), field, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, value)
+		}
+
+		for i, item := range items {
+			if err := r.validate(schema["items"].(object), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, value)
+		}
+
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, text); err != nil {
+				return fmt.Errorf("%s: %q is not date-time", path, text)
+			}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", path, value)
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return fmt.Errorf("%s: expected integer, got %v", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	}
+
+	return nil
+}
+
+// merge объединяет части allOf в одну объектную схему, чтобы свойства
+// одной части не считались незадокументированными в другой.
+func (r spec) merge(allOf []interface{}) object {
+	properties := object{}
+	required := []interface{}{}
+
+	for _, part := range allOf {
+		part := r.resolve(part.(object))
+
+		for name, property := range part["properties"].(object) {
+			properties[name] = property
+		}
+
+		if values, ok := part["required"].([]interface{}); ok {
+			required = append(required, values...)
+		}
+	}
+
+	return object{"type": "object", "properties": properties, "required": required}
+}
+
+// validateResponse находит операцию по шаблону маршрута и проверяет, что код
+// ответа задокументирован, а тело соответствует схеме своего Content-Type.
+func (r spec) validateResponse(route string, resp *http.Response, body []byte) error {
+	operation, ok := r.paths()[route].(object)[strings.ToLower(resp.Request.Method)].(object)
+	if !ok {
+		return fmt.Errorf("%s %s is not documented", resp.Request.Method, route)
+	}
+
+	response, ok := operation["responses"].(object)[strconv.Itoa(resp.StatusCode)].(object)
+	if !ok {
+		return fmt.Errorf("%s %s: status %d is not documented", resp.Request.Method, route, resp.StatusCode)
+	}
+
+	if ref, ok := response["$ref"].(string); ok {
+		response = r.resolve(object{"$ref": ref})
+	}
+
+	content, ok := response["content"].(object)
+	if !ok {
+		if len(body) > 0 {
+			return fmt.Errorf("%s %s: status %d must have no body", resp.Request.Method, route, resp.StatusCode)
+		}
+
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	media, ok := content[mediaType].(object)
+	if !ok {
+		return fmt.Errorf("%s %s: content type %q is not documented for status %d", resp.Request.Method, route, mediaType, resp.StatusCode)
+	}
+
+	schema := media["schema"].(object)
+
+	switch {
+	case mediaType == "application/x-ndjson":
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			if err := r.validateJSON(schema, scanner.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		return scanner.Err()
+	case strings.HasSuffix(mediaType, "json"):
+		return r.validateJSON(schema, body)
+	default:
+		return nil
+	}
+}
+
+func (r spec) validateJSON(schema object, data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	return r.validate(schema, value, "$")
+}
+
+func toStrings(value interface{}) (result []string) {
+	values, _ := value.([]interface{})
+	for _, item := range values {
+		result = append(result, item.(string))
+	}
+
+	return result
+}
+
+// fakeRepo сам служит транзакцией: InTx передаёт его в fn.
+type fakeRepo struct {
+	repositories.Repo
+	repositories.Tx
+}
+
+func (r *fakeRepo) InTx(ctx context.Context, fn func(repositories.Tx) error) error {
+	return fn(r)
+}
+
+func (r *fakeRepo) SaveOrderEvent(ctx context.Context, orderID int, event models.OrderEvent) error {
+	return nil
+}
+
+func (r *fakeRepo) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) error {
+	return nil
+}
+
+func (r *fakeRepo) SaveClient(ctx context.Context, client models.Client) (int, error) {
+	if client.Login == "taken" {
+		return 0, repositories.ErrLoginIsAlreadyTaken
+	}
+
+	return 1, nil
+}
+
+func (r *fakeRepo) FindClient(ctx context.Context, client models.Client) (int, error) {
+	if client.Password != "secret" {
+		return 0, repositories.ErrInvalidLoginPasswordPair
+	}
+
+	return 1, nil
+}
+
+func (r *fakeRepo) InsertOrder(ctx context.Context, order *models.Order) error {
+	switch order.Number {
+	case "2377225624":
+		return repositories.ErrOrderNumberUploadedAnotherClient
+	case "9278923470":
+		return repositories.ErrOrderNumberUploadedThisClient
+	}
+
+	order.ID, order.Status, order.UploadedAt = 3, models.StatusNew, "2020-12-12T10:00:00+03:00"
+
+	return nil
+}
+
+func (r *fakeRepo) orders() []models.Order {
+	return []models.Order{
+		{ID: 1, ClientID: 1, Number: "12345678903", Status: "PROCESSED", Accrual: 500, UploadedAt: "2020-12-10T15:15:45+03:00", Amount: 10000, StoreID: "store-1"},
+		{ID: 2, ClientID: 1, Number: "9278923470", Status: "NEW", UploadedAt: "2020-12-10T15:16:45+03:00"},
+	}
+}
+
+func (r *fakeRepo) FindOrders(ctx context.Context, client models.Client) ([]models.Order, error) {
+	return r.orders(), nil
+}
+
+func (r *fakeRepo) FindOrdersPage(ctx context.Context, client models.Client, query models.ListQuery) (models.OrdersPage, error) {
+	return models.OrdersPage{Orders: r.orders()[:1], Next: &models.Cursor{At: time.Now(), ID: 1}}, nil
+}
+
+func (r *fakeRepo) FindOrder(ctx context.Context, client models.Client, number string) (models.Order, error) {
+	for _, order := range r.orders() {
+		if order.Number == number {
+			return order, nil
+		}
+	}
+
+	return models.Order{}, repositories.ErrOrderNotFound
+}
+
+func (r *fakeRepo) FindOrderByID(ctx context.Context, client models.Client, id int) (models.Order, error) {
+	for _, order := range r.orders() {
+		if order.ID == id {
+			return order, nil
+		}
+	}
+
+	return models.Order{}, repositories.ErrOrderNotFound
+}
+
+func (r *fakeRepo) FindOrderEvents(ctx context.Context, order models.Order) ([]models.OrderEvent, error) {
+	return []models.OrderEvent{
+		{Event: models.OrderEventUploaded, Status: "NEW", CreatedAt: "2020-12-10T15:15:45+03:00"},
+		{Event: models.OrderEventFinal, StatusFrom: "NEW", Status: "PROCESSED", Accrual: 500, CreatedAt: "2020-12-10T15:16:00+03:00"},
+	}, nil
+}
+
+func (r *fakeRepo) FindBalanceForUpdate(ctx context.Context, client models.Client) (*models.Balace, error) {
+	return r.FindBalance(ctx, client)
+}
+
+func (r *fakeRepo) InsertWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	withdrawal.ID, withdrawal.ProcessedAt = 2, "2020-12-12T10:00:00+03:00"
+
+	return nil
+}
+
+func (r *fakeRepo) FindWithdrawals(ctx context.Context, client models.Client) ([]models.Withdrawal, error) {
+	return []models.Withdrawal{}, nil
+}
+
+func (r *fakeRepo) FindWithdrawalsPage(ctx context.Context, client models.Client, query models.ListQuery) (models.WithdrawalsPage, error) {
+	return models.WithdrawalsPage{Withdrawals: []models.Withdrawal{
+		{ID: 1, ClientID: 1, Order: "2377225624", Sum: 100, ProcessedAt: "2020-12-11T10:00:00+03:00"},
+	}}, nil
+}
+
+func (r *fakeRepo) StreamStatement(ctx context.Context, client models.Client, from, to time.Time, fn func(models.StatementEntry) error) error {
+	entries := []models.StatementEntry{
+		{At: "2020-12-10T15:16:00+03:00", Type: models.StatementAccrual, Order: "12345678903", Amount: 500, Balance: 500},
+		{At: "2020-12-11T10:00:00+03:00", Type: models.StatementWithdrawal, Order: "2377225624", Amount: -100, Balance: 400},
+	}
+
+	for _, entry := range entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeRepo) FindBalance(ctx context.Context, client models.Client) (*models.Balace, error) {
+	return &models.Balace{Current: 400, Withdrawn: 100}, nil
+}
+
+func (r *fakeRepo) FindBalanceAt(ctx context.Context, client models.Client, at time.Time) (*models.Balace, error) {
+	return &models.Balace{Current: 500}, nil
+}
+
+func (r *fakeRepo) FindStatements(ctx context.Context, client models.Client) ([]models.MonthlyStatement, error) {
+	return []models.MonthlyStatement{
+		{ID: 1, ClientID: 1, Period: "2020-12", Accruals: 500, Withdrawals: 100, ClosingBalance: 400, CreatedAt: "2021-01-01T00:00:01Z"},
+	}, nil
+}
+
+func (r *fakeRepo) FindStatement(ctx context.Context, client models.Client, period time.Time) (models.MonthlyStatement, error) {
+	statements, _ := r.FindStatements(ctx, client)
+	for _, statement := range statements {
+		if statement.Period == period.Format(models.StatementPeriodLayout) {
+			return statement, nil
+		}
+	}
+
+	return models.MonthlyStatement{}, repositories.ErrStatementNotFound
+}
+
+func (r *fakeRepo) FindTask(ctx context.Context, number string) (models.Task, error) {
+	return models.Task{}, repositories.ErrOrderNotFound
+}
+
+func (r *fakeRepo) FindTaskForUpdate(ctx context.Context, number string) (models.Task, error) {
+	return r.FindTask(ctx, number)
+}
+
+func (r *fakeRepo) SaveWebhook(ctx context.Context, subscription *models.WebhookSubscription) error {
+	subscription.ID = 1
+	subscription.CreatedAt = "2021-01-01T00:00:00Z"
+
+	return nil
+}
+
+func (r *fakeRepo) FindWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return []models.WebhookSubscription{
+		{ID: 1, URL: "https://example.com/hook", Secret: "secret", EventTypes: []string{models.EventPointsCredited}, CreatedAt: "2021-01-01T00:00:00Z"},
+	}, nil
+}
+
+func (r *fakeRepo) DeleteWebhook(ctx context.Context, id int) error {
+	return repositories.ErrWebhookNotFound
+}
+
+func (r *fakeRepo) FindWebhookDeliveries(ctx context.Context, subscriptionID int, limit int) ([]models.WebhookDelivery, error) {
+	return []models.WebhookDelivery{
+		{ID: 1, SubscriptionID: 1, EventID: 7, EventType: models.EventPointsCredited, State: models.DeliveryDelivered,
+			Attempts: 1, LastStatusCode: 200, DeliveredAt: "2021-01-01T00:00:01Z", CreatedAt: "2021-01-01T00:00:00Z"},
+	}, nil
+}
+
+func (r *fakeRepo) ReplayWebhook(ctx context.Context, subscriptionID int, fromEventID int64) (int, error) {
+	return 3, nil
+}
+
+func (r *fakeRepo) PoolStats() models.PoolStats {
+	return models.PoolStats{MaxOpenConnections: 20, OpenConnections: 2, InUse: 1, Idle: 1}
+}
+
+func newRouter(t *testing.T) chi.Router {
+	t.Helper()
+
+	mConfig := &config.Config{AdminToken: adminToken, AccrualWebhookSecret: accrualSecret}
+
+	tokenAuth, err := router.NewTokenAuth()
+	require.NoError(t, err)
+
+	r, err := router.NewRouter(context.Background(), mConfig, tokenAuth, &fakeRepo{}, events.NewBus(16), logger.NewLogger(io.Discard))
+	require.NoError(t, err)
+
+	return r
+}
+
+var pathParam = regexp.MustCompile(`\{[^/]+\}`)
+
+func TestRoutesDocumented(t *testing.T) {
+	s := loadSpec(t)
+	r := newRouter(t)
+
+	routed := map[string]bool{}
+
+	err := chi.Walk(r, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = strings.TrimSuffix(route, "/")
+		if route == "" {
+			route = "/"
+		}
+
+		routed[method+" "+pathParam.ReplaceAllString(route, "{}")] = true
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	documented := map[string]bool{}
+
+	for path, item := range s.paths() {
+		for method := range item.(object) {
+			documented[strings.ToUpper(method)+" "+pathParam.ReplaceAllString(path, "{}")] = true
+		}
+	}
+
+	assert.Equal(t, keys(routed), keys(documented))
+}
+
+func keys(m map[string]bool) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func TestContract(t *testing.T) {
+	s := loadSpec(t)
+	server := httptest.NewServer(newRouter(t))
+	defer server.Close()
+
+	client := server.Client()
+
+	do := func(t *testing.T, method, target, contentType, body string, header http.Header) (*http.Response, []byte) {
+		t.Helper()
+
+		req, err := http.NewRequest(method, server.URL+target, strings.NewReader(body))
+		require.NoError(t, err)
+
+		for name, values := range header {
+			req.Header[name] = values
+		}
+
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, data
+	}
+
+	resp, _ := do(t, http.MethodPost, "/api/user/register", "application/json", `{"login":"user","password":"secret"}`, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, resp.Cookies())
+
+	user := http.Header{"Cookie": []string{resp.Cookies()[0].String()}}
+	admin := http.Header{"Authorization": []string{"Bearer " + adminToken}}
+	accrualBody := `{"order":"12345678903","status":"PROCESSED","accrual":500}`
+	accrual := http.Header{"X-Accrual-Signature": []string{utils.Sign([]byte(accrualSecret), []byte(accrualBody))}}
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		route       string
+		contentType string
+		body        string
+		header      http.Header
+		want        int
+	}{
+		{"index", http.MethodGet, "/", "/", "", "", nil, http.StatusOK},
+		{"spec", http.MethodGet, "/api/openapi.json", "/api/openapi.json", "", "", nil, http.StatusOK},
+		{"docs", http.MethodGet, "/api/docs", "/api/docs", "", "", nil, http.StatusOK},
+		{"register taken", http.MethodPost, "/api/user/register", "/api/user/register", "application/json", `{"login":"taken","password":"secret"}`, nil, http.StatusConflict},
+		{"register malformed", http.MethodPost, "/api/user/register", "/api/user/register", "application/json", `{`, nil, http.StatusBadRequest},
+		{"login", http.MethodPost, "/api/user/login", "/api/user/login", "application/json", `{"login":"user","password":"secret"}`, nil, http.StatusOK},
+		{"login wrong password", http.MethodPost, "/api/user/login", "/api/user/login", "application/json", `{"login":"user","password":"wrong"}`, nil, http.StatusUnauthorized},
+		{"upload order", http.MethodPost, "/api/user/orders", "/api/user/orders", "text/plain", "12345678903", user, http.StatusAccepted},
+		{"upload invalid order", http.MethodPost, "/api/user/orders", "/api/user/orders", "text/plain", "12345678904", user, http.StatusUnprocessableEntity},
+		{"upload foreign order", http.MethodPost, "/api/user/orders", "/api/user/orders", "text/plain", "2377225624", user, http.StatusConflict},
+		{"upload unauthorized", http.MethodPost, "/api/user/orders", "/api/user/orders", "text/plain", "12345678903", nil, http.StatusUnauthorized},
+		{"upload batch", http.MethodPost, "/api/user/orders/batch", "/api/user/orders/batch", "application/json", `["12345678903","1"]`, user, http.StatusOK},
+		{"orders", http.MethodGet, "/api/user/orders", "/api/user/orders", "", "", user, http.StatusOK},
+		{"orders page", http.MethodGet, "/api/user/orders?limit=1", "/api/user/orders", "", "", user, http.StatusOK},
+		{"orders bad limit", http.MethodGet, "/api/user/orders?limit=0", "/api/user/orders", "", "", user, http.StatusBadRequest},
+		{"order timeline", http.MethodGet, "/api/user/orders/12345678903", "/api/user/orders/{number}", "", "", user, http.StatusOK},
+		{"order not found", http.MethodGet, "/api/user/orders/346436439", "/api/user/orders/{number}", "", "", user, http.StatusNotFound},
+		{"balance", http.MethodGet, "/api/user/balance", "/api/user/balance", "", "", user, http.StatusOK},
+		{"balance at", http.MethodGet, "/api/user/balance?at=2020-12-10T16:00:00Z", "/api/user/balance", "", "", user, http.StatusOK},
+		{"balance bad at", http.MethodGet, "/api/user/balance?at=yesterday", "/api/user/balance", "", "", user, http.StatusBadRequest},
+		{"withdraw", http.MethodPost, "/api/user/balance/withdraw", "/api/user/balance/withdraw", "application/json", `{"order":"2377225624","sum":100}`, user, http.StatusOK},
+		{"withdraw too much", http.MethodPost, "/api/user/balance/withdraw", "/api/user/balance/withdraw", "application/json", `{"order":"2377225624","sum":1000}`, user, http.StatusPaymentRequired},
+		{"withdraw invalid order", http.MethodPost, "/api/user/balance/withdraw", "/api/user/balance/withdraw", "application/json", `{"order":"1","sum":100}`, user, http.StatusUnprocessableEntity},
+		{"withdrawals", http.MethodGet, "/api/user/balance/withdrawals", "/api/user/balance/withdrawals", "", "", user, http.StatusNoContent},
+		{"withdrawals page", http.MethodGet, "/api/user/balance/withdrawals?sort=desc", "/api/user/balance/withdrawals", "", "", user, http.StatusOK},
+		{"statement csv", http.MethodGet, "/api/user/statement", "/api/user/statement", "", "", user, http.StatusOK},
+		{"statement jsonl", http.MethodGet, "/api/user/statement?format=jsonl", "/api/user/statement", "", "", user, http.StatusOK},
+		{"statement bad format", http.MethodGet, "/api/user/statement?format=xml", "/api/user/statement", "", "", user, http.StatusBadRequest},
+		{"statements", http.MethodGet, "/api/user/statements", "/api/user/statements", "", "", user, http.StatusOK},
+		{"monthly statement", http.MethodGet, "/api/user/statements/2020-12", "/api/user/statements/{period}", "", "", user, http.StatusOK},
+		{"monthly statement not found", http.MethodGet, "/api/user/statements/2020-11", "/api/user/statements/{period}", "", "", user, http.StatusNotFound},
+		{"graphql", http.MethodPost, "/api/graphql", "/api/graphql", "application/json", `{"query":"{ me { id balance { current withdrawn } } }"}`, user, http.StatusOK},
+		{"graphql malformed", http.MethodPost, "/api/graphql", "/api/graphql", "application/json", `{`, user, http.StatusBadRequest},
+		{"graphql unauthorized", http.MethodPost, "/api/graphql", "/api/graphql", "application/json", `{"query":"{ me { id } }"}`, nil, http.StatusUnauthorized},
+		{"v2 create order", http.MethodPost, "/api/v2/orders", "/api/v2/orders", "application/json", `{"number":"12345678903","amount":1000,"store_id":"store-1"}`, user, http.StatusCreated},
+		{"v2 create order again", http.MethodPost, "/api/v2/orders", "/api/v2/orders", "application/json", `{"number":"9278923470"}`, user, http.StatusOK},
+		{"v2 create order bad amount", http.MethodPost, "/api/v2/orders", "/api/v2/orders", "application/json", `{"number":"12345678903","amount":-1}`, user, http.StatusUnprocessableEntity},
+		{"v2 create foreign order", http.MethodPost, "/api/v2/orders", "/api/v2/orders", "application/json", `{"number":"2377225624"}`, user, http.StatusConflict},
+		{"v2 orders", http.MethodGet, "/api/v2/orders?limit=1", "/api/v2/orders", "", "", user, http.StatusOK},
+		{"v2 orders unauthorized", http.MethodGet, "/api/v2/orders", "/api/v2/orders", "", "", nil, http.StatusUnauthorized},
+		{"v2 order", http.MethodGet, "/api/v2/orders/1", "/api/v2/orders/{id}", "", "", user, http.StatusOK},
+		{"v2 order bad id", http.MethodGet, "/api/v2/orders/abc", "/api/v2/orders/{id}", "", "", user, http.StatusBadRequest},
+		{"v2 order not found", http.MethodGet, "/api/v2/orders/9", "/api/v2/orders/{id}", "", "", user, http.StatusNotFound},
+		{"v2 balance", http.MethodGet, "/api/v2/balance", "/api/v2/balance", "", "", user, http.StatusOK},
+		{"v2 withdraw", http.MethodPost, "/api/v2/withdrawals", "/api/v2/withdrawals", "application/json", `{"order":"2377225624","sum":100}`, user, http.StatusCreated},
+		{"v2 withdraw too much", http.MethodPost, "/api/v2/withdrawals", "/api/v2/withdrawals", "application/json", `{"order":"2377225624","sum":1000}`, user, http.StatusPaymentRequired},
+		{"v2 withdrawals", http.MethodGet, "/api/v2/withdrawals", "/api/v2/withdrawals", "", "", user, http.StatusOK},
+		{"accrual webhook", http.MethodPost, "/api/accrual/orders", "/api/accrual/orders", "application/json", accrualBody, accrual, http.StatusNotFound},
+		{"accrual webhook unsigned", http.MethodPost, "/api/accrual/orders", "/api/accrual/orders", "application/json", accrualBody, nil, http.StatusUnauthorized},
+		{"create webhook", http.MethodPost, "/api/admin/webhooks", "/api/admin/webhooks", "application/json", `{"url":"https://example.com/hook","secret":"s"}`, admin, http.StatusCreated},
+		{"create invalid webhook", http.MethodPost, "/api/admin/webhooks", "/api/admin/webhooks", "application/json", `{"url":"ftp://example.com","secret":"s"}`, admin, http.StatusUnprocessableEntity},
+		{"webhooks", http.MethodGet, "/api/admin/webhooks", "/api/admin/webhooks", "", "", admin, http.StatusOK},
+		{"webhooks unauthorized", http.MethodGet, "/api/admin/webhooks", "/api/admin/webhooks", "", "", nil, http.StatusUnauthorized},
+		{"delete webhook", http.MethodDelete, "/api/admin/webhooks/1", "/api/admin/webhooks/{id}", "", "", admin, http.StatusNotFound},
+		{"webhook deliveries", http.MethodGet, "/api/admin/webhooks/1/deliveries", "/api/admin/webhooks/{id}/deliveries", "", "", admin, http.StatusOK},
+		{"replay webhook", http.MethodPost, "/api/admin/webhooks/1/replay", "/api/admin/webhooks/{id}/replay", "application/json", `{"from_event_id":1}`, admin, http.StatusAccepted},
+		{"db stats", http.MethodGet, "/api/admin/db/stats", "/api/admin/db/stats", "", "", admin, http.StatusOK},
+		{"db stats unauthorized", http.MethodGet, "/api/admin/db/stats", "/api/admin/db/stats", "", "", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := do(t, tt.method, tt.target, tt.contentType, tt.body, tt.header)
+			assert.Equal(t, tt.want, resp.StatusCode, string(body))
+			assert.NoError(t, s.validateResponse(tt.route, resp, body))
+		})
+	}
+}
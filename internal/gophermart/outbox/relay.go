@@ -0,0 +1,152 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+const (
+	defaultInterval = 500 * time.Millisecond
+	defaultBatch    = 100
+)
+
+type Sink interface {
+	Name() string
+	Publish(context.Context, models.OutboxEvent) error
+}
+
+// DurableSink — приёмник, для которого позиция в outbox сохраняется в базе данных,
+// чтобы после перезапуска доставить все пропущенные события.
+type DurableSink interface {
+	Sink
+	Durable() bool
+}
+
+type Relay struct {
+	Repo     repositories.OutboxRepo
+	Sinks    []Sink
+	Logger   *logger.Logger
+	Interval time.Duration
+	Batch    int
+
+	cursors map[string]int64
+}
+
+func NewRelay(repo repositories.OutboxRepo, mLogger *logger.Logger, sinks ...Sink) *Relay {
+	return &Relay{
+		Repo:     repo,
+		Sinks:    sinks,
+		Logger:   mLogger,
+		Interval: defaultInterval,
+		Batch:    defaultBatch,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Process(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Relay) Process(ctx context.Context) {
+	for _, sink := range r.Sinks {
+		if err := r.processSink(ctx, sink); err != nil {
+			r.Logger.Warning(fmt.Sprintf("outbox sink %s: %s", sink.Name(), err))
+		}
+	}
+}
+
+func (r *Relay) processSink(ctx context.Context, sink Sink) (err error) {
+	cursor, err := r.cursor(ctx, sink)
+	if err != nil {
+		return err
+	}
+
+	outboxEvents, err := r.Repo.FindOutboxEvents(ctx, cursor, r.Batch)
+	if err != nil {
+		return err
+	}
+
+	published := cursor
+
+	defer func() {
+		if published == cursor {
+			return
+		}
+
+		// Если позицию не удалось сохранить, события будут отправлены повторно.
+		if isDurable(sink) {
+			if saveErr := r.Repo.SaveOutboxCursor(ctx, sink.Name(), published); saveErr != nil {
+				if err == nil {
+					err = saveErr
+				}
+
+				return
+			}
+		}
+
+		r.cursors[sink.Name()] = published
+	}()
+
+	for _, event := range outboxEvents {
+		if err = sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("event %d: %w", event.ID, err)
+		}
+
+		published = event.ID
+	}
+
+	return nil
+}
+
+func (r *Relay) cursor(ctx context.Context, sink Sink) (int64, error) {
+	if r.cursors == nil {
+		r.cursors = make(map[string]int64)
+	}
+
+	if cursor, ok := r.cursors[sink.Name()]; ok {
+		return cursor, nil
+	}
+
+	var (
+		cursor int64
+		err    error
+	)
+
+	if isDurable(sink) {
+		cursor, err = r.Repo.FindOutboxCursor(ctx, sink.Name())
+		if errors.Is(err, repositories.ErrOutboxCursorNotFound) {
+			cursor, err = 0, nil
+		}
+	} else {
+		cursor, err = r.Repo.FindLastOutboxEventID(ctx)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	r.cursors[sink.Name()] = cursor
+
+	return cursor, nil
+}
+
+func isDurable(sink Sink) bool {
+	durable, ok := sink.(DurableSink)
+
+	return ok && durable.Durable()
+}
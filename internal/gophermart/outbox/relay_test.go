@@ -0,0 +1,164 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/outbox"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+type fakeRepo struct {
+	repositories.Repo
+	outboxEvents []models.OutboxEvent
+	cursors      map[string]int64
+	balance      models.Balace
+	saveErr      error
+}
+
+func (r *fakeRepo) FindOutboxEvents(ctx context.Context, afterEventID int64, limit int) ([]models.OutboxEvent, error) {
+	result := make([]models.OutboxEvent, 0)
+
+	for _, event := range r.outboxEvents {
+		if event.ID > afterEventID && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *fakeRepo) FindClientOutboxEvents(ctx context.Context, clientID int, afterEventID int64, limit int) ([]models.OutboxEvent, error) {
+	result := make([]models.OutboxEvent, 0)
+
+	for _, event := range r.outboxEvents {
+		if event.ID > afterEventID && event.ClientID == clientID && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *fakeRepo) FindLastOutboxEventID(ctx context.Context) (int64, error) {
+	if len(r.outboxEvents) == 0 {
+		return 0, nil
+	}
+
+	return r.outboxEvents[len(r.outboxEvents)-1].ID, nil
+}
+
+func (r *fakeRepo) FindOutboxCursor(ctx context.Context, sink string) (int64, error) {
+	cursor, ok := r.cursors[sink]
+	if !ok {
+		return 0, repositories.ErrOutboxCursorNotFound
+	}
+
+	return cursor, nil
+}
+
+func (r *fakeRepo) SaveOutboxCursor(ctx context.Context, sink string, eventID int64) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+
+	r.cursors[sink] = eventID
+
+	return nil
+}
+
+func (r *fakeRepo) FindBalance(ctx context.Context, client models.Client) (*models.Balace, error) {
+	balance := r.balance
+
+	return &balance, nil
+}
+
+type fakeSink struct {
+	name      string
+	durable   bool
+	failOn    int64
+	published []int64
+}
+
+func (r *fakeSink) Name() string {
+	return r.name
+}
+
+func (r *fakeSink) Durable() bool {
+	return r.durable
+}
+
+func (r *fakeSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	if event.ID == r.failOn {
+		r.failOn = 0
+
+		return errors.New("sink is unavailable")
+	}
+
+	r.published = append(r.published, event.ID)
+
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	repo := &fakeRepo{
+		outboxEvents: []models.OutboxEvent{{ID: 1}, {ID: 2}, {ID: 3}},
+		cursors:      map[string]int64{"webhook": 1},
+	}
+
+	durable := &fakeSink{name: "webhook", durable: true, failOn: 3}
+	fresh := &fakeSink{name: "file", durable: true}
+	live := &fakeSink{name: "bus"}
+
+	relay := outbox.NewRelay(repo, logger.NewLogger(io.Discard), durable, fresh, live)
+	ctx := context.Background()
+
+	relay.Process(ctx)
+
+	assert.Equal(t, []int64{2}, durable.published)
+	assert.Equal(t, int64(2), repo.cursors["webhook"])
+	assert.Equal(t, []int64{1, 2, 3}, fresh.published)
+	assert.Equal(t, int64(3), repo.cursors["file"])
+	assert.Empty(t, live.published)
+	assert.NotContains(t, repo.cursors, "bus")
+
+	repo.outboxEvents = append(repo.outboxEvents, models.OutboxEvent{ID: 4})
+
+	relay.Process(ctx)
+
+	assert.Equal(t, []int64{2, 3, 4}, durable.published)
+	assert.Equal(t, int64(4), repo.cursors["webhook"])
+	assert.Equal(t, []int64{1, 2, 3, 4}, fresh.published)
+	assert.Equal(t, []int64{4}, live.published)
+}
+
+func TestRelayCursorSaveError(t *testing.T) {
+	repo := &fakeRepo{
+		outboxEvents: []models.OutboxEvent{{ID: 1}, {ID: 2}},
+		cursors:      map[string]int64{},
+		saveErr:      errors.New("connection reset by peer"),
+	}
+
+	sink := &fakeSink{name: "webhook", durable: true}
+
+	relay := outbox.NewRelay(repo, logger.NewLogger(io.Discard), sink)
+	ctx := context.Background()
+
+	relay.Process(ctx)
+
+	assert.Equal(t, []int64{1, 2}, sink.published)
+	assert.NotContains(t, repo.cursors, "webhook")
+
+	repo.saveErr = nil
+
+	relay.Process(ctx)
+
+	// Позиция не сохранилась, поэтому события отправляются повторно.
+	assert.Equal(t, []int64{1, 2, 1, 2}, sink.published)
+	assert.Equal(t, int64(2), repo.cursors["webhook"])
+}
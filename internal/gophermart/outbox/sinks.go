@@ -0,0 +1,213 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/vukit/gomac/internal/gophermart/events"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+var ErrUnknownSink = errors.New("unknown outbox sink")
+
+type LogSink struct {
+	Logger *logger.Logger
+}
+
+func (r LogSink) Name() string {
+	return "log"
+}
+
+func (r LogSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	data, err := models.MarshalOutboxEvent(event)
+	if err != nil {
+		return err
+	}
+
+	r.Logger.Info(string(data))
+
+	return nil
+}
+
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+func (r *FileSink) Name() string {
+	return "file"
+}
+
+func (r *FileSink) Durable() bool {
+	return true
+}
+
+func (r *FileSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	data, err := models.MarshalOutboxEvent(event)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err = r.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return r.file.Sync()
+}
+
+func (r *FileSink) Close() error {
+	return r.file.Close()
+}
+
+type WebhookSink struct {
+	Repo repositories.WebhookRepo
+}
+
+func (r WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (r WebhookSink) Durable() bool {
+	return true
+}
+
+func (r WebhookSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	return r.Repo.EnqueueWebhookDeliveries(ctx, event)
+}
+
+type BusSink struct {
+	Bus  *events.Bus
+	Repo repositories.Repo
+}
+
+func (r BusSink) Name() string {
+	return "bus"
+}
+
+func (r BusSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	busEvent, ok, err := toBusEvent(ctx, r.Repo, event)
+	if err != nil || !ok {
+		return err
+	}
+
+	r.Bus.PublishID(busEvent.ID, busEvent.ClientID, busEvent.Type, busEvent.Data)
+
+	return nil
+}
+
+// BusReplay восстанавливает события шины из outbox. Идентификаторы событий
+// шины совпадают с event_id, поэтому Last-Event-ID находится и после
+// перезапуска.
+func BusReplay(repo repositories.Repo) events.Replay {
+	return func(ctx context.Context, clientID int, afterEventID uint64, limit int) ([]events.Event, error) {
+		replayed := make([]events.Event, 0)
+		cursor := int64(afterEventID)
+
+		for len(replayed) < limit {
+			outboxEvents, err := repo.FindClientOutboxEvents(ctx, clientID, cursor, limit)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, outboxEvent := range outboxEvents {
+				busEvent, ok, err := toBusEvent(ctx, repo, outboxEvent)
+				if err != nil {
+					return nil, err
+				}
+
+				if ok && len(replayed) < limit {
+					replayed = append(replayed, busEvent)
+				}
+
+				cursor = outboxEvent.ID
+			}
+
+			if len(outboxEvents) < limit {
+				break
+			}
+		}
+
+		return replayed, nil
+	}
+}
+
+func toBusEvent(ctx context.Context, repo repositories.Repo, event models.OutboxEvent) (busEvent events.Event, ok bool, err error) {
+	busEvent = events.Event{ID: uint64(event.ID), ClientID: event.ClientID}
+
+	switch event.Type {
+	case models.EventOrderUploaded:
+		payload := models.OrderUploaded{}
+		if err = json.Unmarshal(event.Payload, &payload); err != nil {
+			return busEvent, false, err
+		}
+
+		busEvent.Type = events.TypeOrder
+		busEvent.Data = events.OrderStatus{Number: payload.Number, Status: string(models.StatusNew)}
+	case models.EventOrderStatusChanged:
+		payload := models.OrderStatusChanged{}
+		if err = json.Unmarshal(event.Payload, &payload); err != nil {
+			return busEvent, false, err
+		}
+
+		busEvent.Type = events.TypeOrder
+		busEvent.Data = events.OrderStatus{Number: payload.Number, Status: string(payload.Status), Accrual: payload.Accrual}
+	case models.EventPointsCredited, models.EventPointsWithdrawn:
+		balance, err := repo.FindBalance(ctx, models.Client{ID: event.ClientID})
+		if err != nil {
+			return busEvent, false, fmt.Errorf("find balance: %w", err)
+		}
+
+		busEvent.Type = events.TypeBalance
+		busEvent.Data = balance
+	default:
+		return busEvent, false, nil
+	}
+
+	return busEvent, true, nil
+}
+
+func NewSinks(names, filePath string, repo repositories.Repo, bus *events.Bus, mLogger *logger.Logger) ([]Sink, error) {
+	sinks := make([]Sink, 0)
+
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "log":
+			sinks = append(sinks, LogSink{Logger: mLogger})
+		case "file":
+			fileSink, err := NewFileSink(filePath)
+			if err != nil {
+				return nil, err
+			}
+
+			sinks = append(sinks, fileSink)
+		case "webhook":
+			sinks = append(sinks, WebhookSink{Repo: repo})
+		case "bus":
+			sinks = append(sinks, BusSink{Bus: bus, Repo: repo})
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSink, name)
+		}
+	}
+
+	return sinks, nil
+}
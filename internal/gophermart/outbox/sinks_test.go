@@ -0,0 +1,105 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/events"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/outbox"
+)
+
+func TestNewSinks(t *testing.T) {
+	mLogger := logger.NewLogger(io.Discard)
+
+	sinks, err := outbox.NewSinks("log, webhook,bus", "", &fakeRepo{}, events.NewBus(0), mLogger)
+	require.NoError(t, err)
+	assert.Len(t, sinks, 3)
+
+	_, err = outbox.NewSinks("kafka", "", &fakeRepo{}, events.NewBus(0), mLogger)
+	assert.ErrorIs(t, err, outbox.ErrUnknownSink)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+
+	sink, err := outbox.NewFileSink(path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, sink.Publish(ctx, models.OutboxEvent{ID: 1, ClientID: 1, Type: models.EventOrderUploaded, Payload: []byte(`{"number":"12345678903"}`)}))
+	require.NoError(t, sink.Publish(ctx, models.OutboxEvent{ID: 2, ClientID: 1, Type: models.EventPointsWithdrawn, Payload: []byte(`{"order":"2377225624","sum":751}`)}))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var event struct {
+		ID   int64           `json:"id"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, int64(2), event.ID)
+	assert.Equal(t, models.EventPointsWithdrawn, event.Type)
+	assert.JSONEq(t, `{"order":"2377225624","sum":751}`, string(event.Data))
+}
+
+func TestBusSink(t *testing.T) {
+	bus := events.NewBus(16)
+	sink := outbox.BusSink{Bus: bus, Repo: &fakeRepo{balance: models.Balace{Current: 500}}}
+	ctx := context.Background()
+
+	stream, _, unsubscribe := bus.Subscribe(1, 0)
+	defer unsubscribe()
+
+	require.NoError(t, sink.Publish(ctx, models.OutboxEvent{ID: 1, ClientID: 1, Type: models.EventOrderStatusChanged,
+		Payload: []byte(`{"number":"12345678903","status_from":"PROCESSING","status":"PROCESSED","accrual":500}`)}))
+	require.NoError(t, sink.Publish(ctx, models.OutboxEvent{ID: 2, ClientID: 1, Type: models.EventPointsCredited,
+		Payload: []byte(`{"order":"12345678903","accrual":500}`)}))
+
+	event := <-stream
+	assert.Equal(t, uint64(1), event.ID)
+	assert.Equal(t, events.TypeOrder, event.Type)
+	assert.Equal(t, events.OrderStatus{Number: "12345678903", Status: "PROCESSED", Accrual: 500}, event.Data)
+
+	event = <-stream
+	assert.Equal(t, events.TypeBalance, event.Type)
+	assert.Equal(t, &models.Balace{Current: 500}, event.Data)
+}
+
+func TestBusReplay(t *testing.T) {
+	repo := &fakeRepo{balance: models.Balace{Current: 500}, outboxEvents: []models.OutboxEvent{
+		{ID: 1, ClientID: 1, Type: models.EventOrderUploaded, Payload: []byte(`{"number":"12345678903"}`)},
+		{ID: 2, ClientID: 2, Type: models.EventOrderUploaded, Payload: []byte(`{"number":"79927398713"}`)},
+		{ID: 3, ClientID: 1, Type: models.EventStatementGenerated, Payload: []byte(`{"period":"2022-05"}`)},
+		{ID: 4, ClientID: 1, Type: models.EventPointsCredited, Payload: []byte(`{"order":"12345678903","accrual":500}`)},
+	}}
+	replay := outbox.BusReplay(repo)
+	ctx := context.Background()
+
+	replayed, err := replay(ctx, 1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, replayed, 2)
+	assert.Equal(t, events.Event{ID: 1, ClientID: 1, Type: events.TypeOrder,
+		Data: events.OrderStatus{Number: "12345678903", Status: "NEW"}}, replayed[0])
+	assert.Equal(t, events.Event{ID: 4, ClientID: 1, Type: events.TypeBalance, Data: &models.Balace{Current: 500}}, replayed[1])
+
+	replayed, err = replay(ctx, 1, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, replayed, 1)
+	assert.Equal(t, uint64(4), replayed[0].ID)
+}
@@ -0,0 +1,97 @@
+package problems
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/vukit/gomac/internal/gophermart/auth"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+const (
+	ContentType = "application/problem+json"
+	TypePrefix  = "urn:gophermart:problem:"
+
+	InternalError = "internal_error"
+)
+
+var ErrMalformedRequest = errors.New("malformed request")
+
+// Problem — тело ответа с ошибкой по RFC 7807; Code — стабильный
+// машиночитаемый код, по которому клиенты различают ошибки.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// Kind сопоставляет ошибку со статусом HTTP и кодом ответа.
+type Kind struct {
+	Err    error
+	Status int
+	Code   string
+}
+
+// kinds — общая таблица для HTTP, gRPC и GraphQL.
+var kinds = []Kind{
+	{ErrMalformedRequest, http.StatusBadRequest, "malformed_request"},
+	{auth.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{auth.ErrNotFindClientID, http.StatusUnauthorized, "unauthorized"},
+
+	{models.ErrLoginPasswordEmpity, http.StatusBadRequest, "empty_credentials"},
+	{models.ErrLongLogin, http.StatusBadRequest, "login_too_long"},
+	{models.ErrInvalidOrderNumberFormat, http.StatusUnprocessableEntity, "invalid_order_number"},
+	{models.ErrWrongWithdrawalSum, http.StatusUnprocessableEntity, "invalid_withdrawal_sum"},
+	{models.ErrUnknownOrderStatus, http.StatusBadRequest, "unknown_order_status"},
+	{models.ErrIllegalStatusTransition, http.StatusConflict, "illegal_status_transition"},
+	{models.ErrInvalidWebhookURL, http.StatusUnprocessableEntity, "invalid_webhook_url"},
+	{models.ErrEmptyWebhookSecret, http.StatusUnprocessableEntity, "empty_webhook_secret"},
+	{models.ErrUnknownEventType, http.StatusUnprocessableEntity, "unknown_event_type"},
+	{models.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{models.ErrInvalidPageLimit, http.StatusBadRequest, "invalid_page_limit"},
+	{models.ErrInvalidDateRange, http.StatusBadRequest, "invalid_date_range"},
+	{models.ErrInvalidMinAccrual, http.StatusBadRequest, "invalid_min_accrual"},
+	{models.ErrInvalidStatementPeriod, http.StatusBadRequest, "invalid_statement_period"},
+	{models.ErrInvalidBalanceTime, http.StatusBadRequest, "invalid_balance_time"},
+	{models.ErrInvalidOrderAmount, http.StatusUnprocessableEntity, "invalid_order_amount"},
+	{models.ErrLongStoreID, http.StatusUnprocessableEntity, "store_id_too_long"},
+
+	{repositories.ErrNoDBConn, http.StatusServiceUnavailable, "database_unavailable"},
+	{repositories.ErrLoginIsAlreadyTaken, http.StatusConflict, "login_taken"},
+	{repositories.ErrInvalidLoginPasswordPair, http.StatusUnauthorized, "invalid_login_password"},
+	{repositories.ErrOrderNumberUploadedAnotherClient, http.StatusConflict, "order_uploaded_by_another_client"},
+	{repositories.ErrThereAreNotEnoughAccrual, http.StatusPaymentRequired, "insufficient_balance"},
+	{repositories.ErrOrderNotFound, http.StatusNotFound, "order_not_found"},
+	{repositories.ErrWebhookNotFound, http.StatusNotFound, "webhook_not_found"},
+	{repositories.ErrStatementNotFound, http.StatusNotFound, "statement_not_found"},
+}
+
+// New сопоставляет ошибку с кодом ответа; extra проверяются раньше общей
+// таблицы и нужны транспортам для их собственных ошибок. Неизвестные
+// ошибки считаются внутренними, и их текст клиенту не раскрывается.
+func New(err error, extra ...Kind) Problem {
+	for _, table := range [][]Kind{extra, kinds} {
+		for _, kind := range table {
+			if errors.Is(err, kind.Err) {
+				return Problem{
+					Type:   TypePrefix + kind.Code,
+					Title:  http.StatusText(kind.Status),
+					Status: kind.Status,
+					Detail: err.Error(),
+					Code:   kind.Code,
+				}
+			}
+		}
+	}
+
+	return Problem{
+		Type:   TypePrefix + InternalError,
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Code:   InternalError,
+	}
+}
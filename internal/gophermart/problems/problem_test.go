@@ -0,0 +1,60 @@
+package problems_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/auth"
+	"github.com/vukit/gomac/internal/gophermart/problems"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+func TestNew(t *testing.T) {
+	errTransport := errors.New("transport error")
+
+	tests := []struct {
+		name   string
+		err    error
+		extra  []problems.Kind
+		status int
+		code   string
+	}{
+		{
+			name:   "case 1",
+			err:    fmt.Errorf("%w: token is expired", auth.ErrUnauthorized),
+			status: http.StatusUnauthorized,
+			code:   "unauthorized",
+		},
+		{
+			name:   "case 2",
+			err:    repositories.ErrOrderNotFound,
+			status: http.StatusNotFound,
+			code:   "order_not_found",
+		},
+		{
+			name:   "case 3",
+			err:    errTransport,
+			extra:  []problems.Kind{{Err: errTransport, Status: http.StatusBadRequest, Code: "transport_error"}},
+			status: http.StatusBadRequest,
+			code:   "transport_error",
+		},
+		{
+			name:   "case 4",
+			err:    errTransport,
+			status: http.StatusInternalServerError,
+			code:   problems.InternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := problems.New(tt.err, tt.extra...)
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, problems.TypePrefix+tt.code, problem.Type)
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+// RepoMemory хранит данные в памяти процесса и повторяет семантику
+// RepoPostgreSQL; используется в режиме разработки и в тестах.
+type RepoMemory struct {
+	mu sync.Mutex
+
+	clients     []memoryClient
+	orders      []memoryOrder
+	events      []memoryOrderEvent
+	withdrawals []memoryWithdrawal
+	outbox      []models.OutboxEvent
+	cursors     map[string]int64
+	webhooks    []models.WebhookSubscription
+	deliveries  []memoryDelivery
+	statements  []memoryStatement
+}
+
+type memoryClient struct {
+	id       int
+	login    string
+	password string
+}
+
+type memoryOrder struct {
+	order      models.Order
+	uploadedAt time.Time
+}
+
+type memoryOrderEvent struct {
+	id        int
+	orderID   int
+	event     models.OrderEvent
+	createdAt time.Time
+}
+
+type memoryWithdrawal struct {
+	withdrawal  models.Withdrawal
+	processedAt time.Time
+}
+
+func NewRepositoryMemory() *RepoMemory {
+	return &RepoMemory{cursors: make(map[string]int64)}
+}
+
+// now возвращает время с точностью до микросекунд, как timestamptz.
+func (repo *RepoMemory) now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func formatTime(value time.Time) string {
+	return value.Format(time.RFC3339Nano)
+}
+
+func passwordHash(password string) string {
+	hash := sha256.Sum256([]byte(password))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func (repo *RepoMemory) SaveClient(ctx context.Context, client models.Client) (clientID int, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, stored := range repo.clients {
+		if stored.login == client.Login {
+			return 0, ErrLoginIsAlreadyTaken
+		}
+	}
+
+	clientID = len(repo.clients) + 1
+	repo.clients = append(repo.clients, memoryClient{id: clientID, login: client.Login, password: passwordHash(client.Password)})
+
+	return clientID, nil
+}
+
+func (repo *RepoMemory) FindClient(ctx context.Context, client models.Client) (clientID int, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	password := passwordHash(client.Password)
+
+	for _, stored := range repo.clients {
+		if stored.login == client.Login && stored.password == password {
+			return stored.id, nil
+		}
+	}
+
+	return 0, ErrInvalidLoginPasswordPair
+}
+
+func (repo *RepoMemory) addOrderEvent(orderID int, event models.OrderEvent, createdAt time.Time) {
+	event.CreatedAt = formatTime(createdAt)
+
+	repo.events = append(repo.events, memoryOrderEvent{
+		id:        len(repo.events) + 1,
+		orderID:   orderID,
+		event:     event,
+		createdAt: createdAt,
+	})
+}
+
+func (repo *RepoMemory) findOrder(match func(models.Order) bool) (*memoryOrder, error) {
+	for i := range repo.orders {
+		if match(repo.orders[i].order) {
+			return &repo.orders[i], nil
+		}
+	}
+
+	return nil, ErrOrderNotFound
+}
+
+func (repo *RepoMemory) clientOrders(clientID int) []memoryOrder {
+	orders := make([]memoryOrder, 0)
+
+	for _, stored := range repo.orders {
+		if stored.order.ClientID == clientID {
+			orders = append(orders, stored)
+		}
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].uploadedAt.Before(orders[j].uploadedAt)
+	})
+
+	return orders
+}
+
+func (repo *RepoMemory) FindOrders(ctx context.Context, client models.Client) (orders []models.Order, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	orders = make([]models.Order, 0)
+
+	for _, stored := range repo.clientOrders(client.ID) {
+		orders = append(orders, models.Order{
+			Number:     stored.order.Number,
+			Accrual:    stored.order.Accrual,
+			Status:     stored.order.Status,
+			UploadedAt: stored.order.UploadedAt,
+		})
+	}
+
+	return orders, nil
+}
+
+func (repo *RepoMemory) FindOrder(ctx context.Context, client models.Client, number string) (order models.Order, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, err := repo.findOrder(func(order models.Order) bool {
+		return order.ClientID == client.ID && order.Number == number
+	})
+	if err != nil {
+		return order, err
+	}
+
+	return stored.order, nil
+}
+
+func (repo *RepoMemory) FindOrderByID(ctx context.Context, client models.Client, id int) (order models.Order, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, err := repo.findOrder(func(order models.Order) bool {
+		return order.ClientID == client.ID && order.ID == id
+	})
+	if err != nil {
+		return order, err
+	}
+
+	return stored.order, nil
+}
+
+func (repo *RepoMemory) FindOrderEvents(ctx context.Context, order models.Order) (events []models.OrderEvent, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	events = make([]models.OrderEvent, 0)
+
+	for _, stored := range repo.orderEvents(order.ID) {
+		events = append(events, stored.event)
+	}
+
+	return events, nil
+}
+
+func (repo *RepoMemory) orderEvents(orderID int) []memoryOrderEvent {
+	events := make([]memoryOrderEvent, 0)
+
+	for _, stored := range repo.events {
+		if stored.orderID == orderID {
+			events = append(events, stored)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].createdAt.Equal(events[j].createdAt) {
+			return events[i].createdAt.Before(events[j].createdAt)
+		}
+
+		return events[i].id < events[j].id
+	})
+
+	return events
+}
+
+func (repo *RepoMemory) FindOrdersByNumbers(ctx context.Context, client models.Client, numbers []string) (orders []models.Order, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	wanted := make(map[string]bool, len(numbers))
+	for _, number := range numbers {
+		wanted[number] = true
+	}
+
+	orders = make([]models.Order, 0, len(numbers))
+
+	for _, stored := range repo.orders {
+		if stored.order.ClientID == client.ID && wanted[stored.order.Number] {
+			orders = append(orders, stored.order)
+		}
+	}
+
+	return orders, nil
+}
+
+func (repo *RepoMemory) FindOrdersEvents(ctx context.Context, orderIDs []int) (events map[int][]models.OrderEvent, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	events = make(map[int][]models.OrderEvent, len(orderIDs))
+
+	for _, orderID := range orderIDs {
+		for _, stored := range repo.orderEvents(orderID) {
+			events[orderID] = append(events[orderID], stored.event)
+		}
+	}
+
+	return events, nil
+}
+
+func (repo *RepoMemory) clientWithdrawals(clientID int) []memoryWithdrawal {
+	withdrawals := make([]memoryWithdrawal, 0)
+
+	for _, stored := range repo.withdrawals {
+		if stored.withdrawal.ClientID == clientID {
+			withdrawals = append(withdrawals, stored)
+		}
+	}
+
+	sort.SliceStable(withdrawals, func(i, j int) bool {
+		return withdrawals[i].processedAt.Before(withdrawals[j].processedAt)
+	})
+
+	return withdrawals
+}
+
+func (repo *RepoMemory) FindWithdrawals(ctx context.Context, client models.Client) (withdrawals []models.Withdrawal, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	withdrawals = make([]models.Withdrawal, 0)
+
+	for _, stored := range repo.clientWithdrawals(client.ID) {
+		withdrawals = append(withdrawals, models.Withdrawal{
+			Order:       stored.withdrawal.Order,
+			Sum:         stored.withdrawal.Sum,
+			ProcessedAt: stored.withdrawal.ProcessedAt,
+		})
+	}
+
+	return withdrawals, nil
+}
+
+func (repo *RepoMemory) FindBalance(ctx context.Context, client models.Client) (balance *models.Balace, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.balance(client.ID), nil
+}
+
+func (repo *RepoMemory) balance(clientID int) *models.Balace {
+	balance := &models.Balace{}
+
+	accruals := float64(0)
+
+	for _, stored := range repo.orders {
+		if stored.order.ClientID == clientID && stored.order.Status == models.StatusProcessed {
+			accruals += stored.order.Accrual
+		}
+	}
+
+	for _, stored := range repo.withdrawals {
+		if stored.withdrawal.ClientID == clientID {
+			balance.Withdrawn += stored.withdrawal.Sum
+		}
+	}
+
+	balance.Current = accruals - balance.Withdrawn
+
+	return balance
+}
+
+func (repo *RepoMemory) FindTask(ctx context.Context, orderNumber string) (task models.Task, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, err := repo.findOrder(func(order models.Order) bool {
+		return order.Number == orderNumber
+	})
+	if err != nil {
+		return task, err
+	}
+
+	return models.Task{
+		OrderID:     stored.order.ID,
+		ClientID:    stored.order.ClientID,
+		OrderNumber: stored.order.Number,
+		Accrual:     stored.order.Accrual,
+		Status:      stored.order.Status,
+	}, nil
+}
+
+func (repo *RepoMemory) Close() error {
+	return nil
+}
+
+func (repo *RepoMemory) Ping(ctx context.Context) error {
+	return nil
+}
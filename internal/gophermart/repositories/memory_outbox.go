@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func (repo *RepoMemory) saveOutboxEvent(clientID int, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	repo.outbox = append(repo.outbox, models.OutboxEvent{
+		ID:        int64(len(repo.outbox) + 1),
+		ClientID:  clientID,
+		Type:      eventType,
+		Payload:   data,
+		CreatedAt: formatTime(repo.now()),
+	})
+
+	return nil
+}
+
+func (repo *RepoMemory) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) (err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.saveOutboxEvent(clientID, eventType, payload)
+}
+
+func (repo *RepoMemory) FindOutboxEvents(ctx context.Context, afterEventID int64, limit int) (outboxEvents []models.OutboxEvent, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	outboxEvents = make([]models.OutboxEvent, 0)
+
+	for _, event := range repo.outbox {
+		if len(outboxEvents) == limit {
+			break
+		}
+
+		if event.ID > afterEventID {
+			outboxEvents = append(outboxEvents, event)
+		}
+	}
+
+	return outboxEvents, nil
+}
+
+func (repo *RepoMemory) FindClientOutboxEvents(ctx context.Context, clientID int, afterEventID int64, limit int) (outboxEvents []models.OutboxEvent, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	outboxEvents = make([]models.OutboxEvent, 0)
+
+	for _, event := range repo.outbox {
+		if len(outboxEvents) == limit {
+			break
+		}
+
+		if event.ID > afterEventID && event.ClientID == clientID {
+			outboxEvents = append(outboxEvents, event)
+		}
+	}
+
+	return outboxEvents, nil
+}
+
+func (repo *RepoMemory) FindLastOutboxEventID(ctx context.Context) (eventID int64, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return int64(len(repo.outbox)), nil
+}
+
+func (repo *RepoMemory) FindOutboxCursor(ctx context.Context, sink string) (eventID int64, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	eventID, ok := repo.cursors[sink]
+	if !ok {
+		return 0, ErrOutboxCursorNotFound
+	}
+
+	return eventID, nil
+}
+
+func (repo *RepoMemory) SaveOutboxCursor(ctx context.Context, sink string, eventID int64) (err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if current, ok := repo.cursors[sink]; !ok || eventID > current {
+		repo.cursors[sink] = eventID
+	}
+
+	return nil
+}
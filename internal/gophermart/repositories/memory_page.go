@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+// pageFilter повторяет условия pageQuery.build: границы периода, курсор и
+// направление сортировки по паре (время, идентификатор).
+func pageFilter(query models.ListQuery, at time.Time, id int) bool {
+	if !query.From.IsZero() && at.Before(query.From) {
+		return false
+	}
+
+	if !query.To.IsZero() && !at.Before(query.To) {
+		return false
+	}
+
+	if query.After != nil {
+		if query.Desc {
+			return pageLess(at, id, query.After.At, query.After.ID)
+		}
+
+		return pageLess(query.After.At, query.After.ID, at, id)
+	}
+
+	return true
+}
+
+func pageLess(at time.Time, id int, otherAt time.Time, otherID int) bool {
+	if !at.Equal(otherAt) {
+		return at.Before(otherAt)
+	}
+
+	return id < otherID
+}
+
+func (repo *RepoMemory) FindOrdersPage(ctx context.Context, client models.Client, query models.ListQuery) (page models.OrdersPage, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	statuses := make(map[models.OrderStatus]bool, len(query.Statuses))
+	for _, status := range query.Statuses {
+		statuses[status] = true
+	}
+
+	orders := make([]memoryOrder, 0)
+
+	for _, stored := range repo.orders {
+		order := stored.order
+
+		switch {
+		case order.ClientID != client.ID:
+		case len(statuses) > 0 && !statuses[order.Status]:
+		case query.MinAccrual > 0 && order.Accrual < query.MinAccrual:
+		case !pageFilter(query, stored.uploadedAt, order.ID):
+		default:
+			orders = append(orders, stored)
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		if query.Desc {
+			i, j = j, i
+		}
+
+		return pageLess(orders[i].uploadedAt, orders[i].order.ID, orders[j].uploadedAt, orders[j].order.ID)
+	})
+
+	page.Orders = make([]models.Order, 0, query.Limit)
+
+	for _, stored := range orders {
+		page.Orders = append(page.Orders, stored.order)
+	}
+
+	if len(page.Orders) > query.Limit {
+		page.Orders = page.Orders[:query.Limit]
+		last := orders[query.Limit-1]
+		page.Next = &models.Cursor{At: last.uploadedAt, ID: last.order.ID}
+	}
+
+	return page, nil
+}
+
+func (repo *RepoMemory) FindWithdrawalsPage(ctx context.Context, client models.Client, query models.ListQuery) (page models.WithdrawalsPage, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	withdrawals := make([]memoryWithdrawal, 0)
+
+	for _, stored := range repo.withdrawals {
+		if stored.withdrawal.ClientID == client.ID && pageFilter(query, stored.processedAt, stored.withdrawal.ID) {
+			withdrawals = append(withdrawals, stored)
+		}
+	}
+
+	sort.Slice(withdrawals, func(i, j int) bool {
+		if query.Desc {
+			i, j = j, i
+		}
+
+		return pageLess(withdrawals[i].processedAt, withdrawals[i].withdrawal.ID, withdrawals[j].processedAt, withdrawals[j].withdrawal.ID)
+	})
+
+	page.Withdrawals = make([]models.Withdrawal, 0, query.Limit)
+
+	for _, stored := range withdrawals {
+		page.Withdrawals = append(page.Withdrawals, stored.withdrawal)
+	}
+
+	if len(page.Withdrawals) > query.Limit {
+		page.Withdrawals = page.Withdrawals[:query.Limit]
+		last := withdrawals[query.Limit-1]
+		page.Next = &models.Cursor{At: last.processedAt, ID: last.withdrawal.ID}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,308 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+type memoryStatement struct {
+	statement models.MonthlyStatement
+	period    string
+	notified  bool
+}
+
+type ledgerEntry struct {
+	clientID int
+	at       time.Time
+	kind     string
+	order    string
+	amount   float64
+	id       int
+}
+
+// ledger повторяет ledgerSQL: начисления датируются переходом заказа в
+// PROCESSED, списания — временем списания. Записи упорядочены по (at, kind, id).
+func (repo *RepoMemory) ledger() []ledgerEntry {
+	entries := make([]ledgerEntry, 0)
+
+	for _, stored := range repo.orders {
+		order := stored.order
+
+		if order.Status != models.StatusProcessed || order.Accrual <= 0 {
+			continue
+		}
+
+		at := stored.uploadedAt
+
+		var processed time.Time
+
+		for _, event := range repo.events {
+			if event.orderID == order.ID && event.event.Status == models.StatusProcessed && event.createdAt.After(processed) {
+				processed = event.createdAt
+			}
+		}
+
+		if !processed.IsZero() {
+			at = processed
+		}
+
+		entries = append(entries, ledgerEntry{
+			clientID: order.ClientID,
+			at:       at,
+			kind:     models.StatementAccrual,
+			order:    order.Number,
+			amount:   order.Accrual,
+			id:       order.ID,
+		})
+	}
+
+	for _, stored := range repo.withdrawals {
+		entries = append(entries, ledgerEntry{
+			clientID: stored.withdrawal.ClientID,
+			at:       stored.processedAt,
+			kind:     models.StatementWithdrawal,
+			order:    stored.withdrawal.Order,
+			amount:   -stored.withdrawal.Sum,
+			id:       stored.withdrawal.ID,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].before(entries[j])
+	})
+
+	return entries
+}
+
+// before сравнивает записи по ключу (at, kind, id).
+func (r ledgerEntry) before(other ledgerEntry) bool {
+	switch {
+	case !r.at.Equal(other.at):
+		return r.at.Before(other.at)
+	case r.kind != other.kind:
+		return r.kind < other.kind
+	default:
+		return r.id < other.id
+	}
+}
+
+func (repo *RepoMemory) StreamStatement(ctx context.Context, client models.Client, from, to time.Time, fn func(models.StatementEntry) error) (err error) {
+	var after *ledgerEntry
+
+	for {
+		entries, last := repo.statementChunk(client, from, to, after)
+
+		for _, entry := range entries {
+			if err = fn(entry); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < statementChunk {
+			return nil
+		}
+
+		after = &last
+	}
+}
+
+// statementChunk отдаёт следующие после after строки выписки; блокировка
+// снимается до вызова fn, поэтому выписка читается частями.
+func (repo *RepoMemory) statementChunk(client models.Client, from, to time.Time, after *ledgerEntry) (entries []models.StatementEntry, last ledgerEntry) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	entries = make([]models.StatementEntry, 0, statementChunk)
+	balance := float64(0)
+
+	// Баланс считается по всей истории клиента, поэтому первая строка
+	// выписки уже учитывает операции до начала периода.
+	for _, entry := range repo.ledger() {
+		if entry.clientID != client.ID {
+			continue
+		}
+
+		balance += entry.amount
+
+		if (!from.IsZero() && entry.at.Before(from)) || (!to.IsZero() && !entry.at.Before(to)) {
+			continue
+		}
+
+		if after != nil && !after.before(entry) {
+			continue
+		}
+
+		entries = append(entries, models.StatementEntry{
+			At:      formatTime(entry.at),
+			Type:    entry.kind,
+			Order:   entry.order,
+			Amount:  entry.amount,
+			Balance: balance,
+		})
+		last = entry
+
+		if len(entries) == statementChunk {
+			break
+		}
+	}
+
+	return entries, last
+}
+
+func (repo *RepoMemory) FindBalanceAt(ctx context.Context, client models.Client, at time.Time) (balance *models.Balace, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	balance = &models.Balace{}
+
+	for _, entry := range repo.ledger() {
+		if entry.clientID != client.ID || entry.at.After(at) {
+			continue
+		}
+
+		balance.Current += entry.amount
+
+		if entry.kind == models.StatementWithdrawal {
+			balance.Withdrawn -= entry.amount
+		}
+	}
+
+	return balance, nil
+}
+
+func (repo *RepoMemory) GenerateStatements(ctx context.Context, period time.Time) (count int, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	end := period.AddDate(0, 1, 0)
+	key := period.Format("2006-01-02")
+
+	generated := make(map[int]*models.MonthlyStatement)
+	clients := make([]int, 0)
+
+	for _, entry := range repo.ledger() {
+		if !entry.at.Before(end) {
+			continue
+		}
+
+		statement, ok := generated[entry.clientID]
+		if !ok {
+			statement = &models.MonthlyStatement{ClientID: entry.clientID, Period: period.Format(models.StatementPeriodLayout)}
+			generated[entry.clientID] = statement
+			clients = append(clients, entry.clientID)
+		}
+
+		statement.ClosingBalance += entry.amount
+
+		switch {
+		case entry.at.Before(period):
+			statement.OpeningBalance += entry.amount
+		case entry.kind == models.StatementAccrual:
+			statement.Accruals += entry.amount
+		default:
+			statement.Withdrawals -= entry.amount
+		}
+	}
+
+	sort.Ints(clients)
+
+	createdAt := formatTime(repo.now())
+
+	for _, clientID := range clients {
+		if _, ok := repo.findStatement(clientID, key); ok {
+			continue
+		}
+
+		statement := generated[clientID]
+		statement.ID = len(repo.statements) + 1
+		statement.CreatedAt = createdAt
+
+		repo.statements = append(repo.statements, memoryStatement{statement: *statement, period: key})
+
+		count++
+	}
+
+	return count, nil
+}
+
+func (repo *RepoMemory) findStatement(clientID int, period string) (*memoryStatement, bool) {
+	for i := range repo.statements {
+		if repo.statements[i].statement.ClientID == clientID && repo.statements[i].period == period {
+			return &repo.statements[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func (repo *RepoMemory) FindStatements(ctx context.Context, client models.Client) (statements []models.MonthlyStatement, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored := make([]memoryStatement, 0)
+
+	for _, statement := range repo.statements {
+		if statement.statement.ClientID == client.ID {
+			stored = append(stored, statement)
+		}
+	}
+
+	sort.SliceStable(stored, func(i, j int) bool {
+		return stored[i].period < stored[j].period
+	})
+
+	statements = make([]models.MonthlyStatement, 0, len(stored))
+
+	for _, statement := range stored {
+		statements = append(statements, statement.statement)
+	}
+
+	return statements, nil
+}
+
+func (repo *RepoMemory) FindStatement(ctx context.Context, client models.Client, period time.Time) (statement models.MonthlyStatement, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.findStatement(client.ID, period.Format("2006-01-02"))
+	if !ok {
+		return statement, ErrStatementNotFound
+	}
+
+	return stored.statement, nil
+}
+
+func (repo *RepoMemory) FindUnnotifiedStatements(ctx context.Context, limit int) (statements []models.MonthlyStatement, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	statements = make([]models.MonthlyStatement, 0)
+
+	for _, stored := range repo.statements {
+		if len(statements) == limit {
+			break
+		}
+
+		if !stored.notified {
+			statements = append(statements, stored.statement)
+		}
+	}
+
+	return statements, nil
+}
+
+func (repo *RepoMemory) MarkStatementNotified(ctx context.Context, id int) (err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for i := range repo.statements {
+		if repo.statements[i].statement.ID == id {
+			repo.statements[i].notified = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+// txMemory работает под блокировкой RepoMemory, которую InTx держит до
+// конца транзакции, поэтому сам ничего не блокирует.
+type txMemory struct {
+	repo *RepoMemory
+}
+
+// InTx при ошибке восстанавливает заказы и отбрасывает добавленные записи:
+// остальные данные транзакции только дописываются в конец.
+func (repo *RepoMemory) InTx(ctx context.Context, fn func(Tx) error) (err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	orders := append([]memoryOrder(nil), repo.orders...)
+	events, withdrawals, outbox := len(repo.events), len(repo.withdrawals), len(repo.outbox)
+
+	if err = fn(txMemory{repo: repo}); err != nil {
+		repo.orders = orders
+		repo.events = repo.events[:events]
+		repo.withdrawals = repo.withdrawals[:withdrawals]
+		repo.outbox = repo.outbox[:outbox]
+
+		return err
+	}
+
+	return nil
+}
+
+func (r txMemory) InsertOrder(ctx context.Context, order *models.Order) error {
+	for _, stored := range r.repo.orders {
+		if stored.order.Number != order.Number {
+			continue
+		}
+
+		if stored.order.ClientID == order.ClientID {
+			return ErrOrderNumberUploadedThisClient
+		}
+
+		return ErrOrderNumberUploadedAnotherClient
+	}
+
+	now := r.repo.now()
+
+	order.ID = len(r.repo.orders) + 1
+	order.Status = models.StatusNew
+	order.UploadedAt = formatTime(now)
+
+	stored := memoryOrder{order: *order, uploadedAt: now}
+	stored.order.Accrual = 0
+
+	r.repo.orders = append(r.repo.orders, stored)
+
+	return nil
+}
+
+func (r txMemory) SaveOrderEvent(ctx context.Context, orderID int, event models.OrderEvent) error {
+	r.repo.addOrderEvent(orderID, event, r.repo.now())
+
+	return nil
+}
+
+func (r txMemory) FindBalanceForUpdate(ctx context.Context, client models.Client) (*models.Balace, error) {
+	return r.repo.balance(client.ID), nil
+}
+
+func (r txMemory) InsertWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	now := r.repo.now()
+
+	withdrawal.ID = len(r.repo.withdrawals) + 1
+	withdrawal.ProcessedAt = formatTime(now)
+
+	r.repo.withdrawals = append(r.repo.withdrawals, memoryWithdrawal{withdrawal: *withdrawal, processedAt: now})
+
+	return nil
+}
+
+func (r txMemory) FindTaskForUpdate(ctx context.Context, orderNumber string) (task models.Task, err error) {
+	stored, err := r.repo.findOrder(func(order models.Order) bool {
+		return order.Number == orderNumber
+	})
+	if err != nil {
+		return task, err
+	}
+
+	return models.Task{
+		OrderID:     stored.order.ID,
+		ClientID:    stored.order.ClientID,
+		OrderNumber: stored.order.Number,
+		Accrual:     stored.order.Accrual,
+		Status:      stored.order.Status,
+	}, nil
+}
+
+func (r txMemory) UpdateTask(ctx context.Context, task models.Task) error {
+	stored, err := r.repo.findOrder(func(order models.Order) bool {
+		return order.ID == task.OrderID
+	})
+	if err != nil {
+		return err
+	}
+
+	stored.order.Accrual = task.Accrual
+	stored.order.Status = task.Status
+
+	return nil
+}
+
+func (r txMemory) FindTasks(ctx context.Context, statuses ...models.OrderStatus) (tasks []models.Task, err error) {
+	wanted := make(map[models.OrderStatus]bool, len(statuses))
+	for _, status := range statuses {
+		wanted[status] = true
+	}
+
+	tasks = make([]models.Task, 0)
+
+	for _, stored := range r.repo.orders {
+		if wanted[stored.order.Status] {
+			tasks = append(tasks, models.Task{OrderID: stored.order.ID, ClientID: stored.order.ClientID, OrderNumber: stored.order.Number})
+		}
+	}
+
+	return tasks, nil
+}
+
+func (r txMemory) PickNewTasks(ctx context.Context) (tasks []models.Task, err error) {
+	tasks = make([]models.Task, 0)
+
+	for i := range r.repo.orders {
+		order := &r.repo.orders[i].order
+		if order.Status != models.StatusNew {
+			continue
+		}
+
+		order.Status = models.StatusProcessing
+
+		tasks = append(tasks, models.Task{
+			OrderID:     order.ID,
+			ClientID:    order.ClientID,
+			OrderNumber: order.Number,
+			Accrual:     order.Accrual,
+			Status:      order.Status,
+		})
+	}
+
+	return tasks, nil
+}
+
+func (r txMemory) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) error {
+	return r.repo.saveOutboxEvent(clientID, eventType, payload)
+}
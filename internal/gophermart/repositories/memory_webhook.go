@@ -0,0 +1,256 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+type memoryDelivery struct {
+	delivery      models.WebhookDelivery
+	nextAttemptAt time.Time
+}
+
+func (repo *RepoMemory) SaveWebhook(ctx context.Context, subscription *models.WebhookSubscription) (err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	subscription.ID = 1
+	if len(repo.webhooks) > 0 {
+		subscription.ID = repo.webhooks[len(repo.webhooks)-1].ID + 1
+	}
+
+	subscription.CreatedAt = formatTime(repo.now())
+
+	stored := *subscription
+	stored.EventTypes = append([]string{}, subscription.EventTypes...)
+
+	repo.webhooks = append(repo.webhooks, stored)
+
+	return nil
+}
+
+func (repo *RepoMemory) FindWebhooks(ctx context.Context) (subscriptions []models.WebhookSubscription, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	subscriptions = make([]models.WebhookSubscription, 0, len(repo.webhooks))
+
+	for _, subscription := range repo.webhooks {
+		subscription.EventTypes = append([]string{}, subscription.EventTypes...)
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, nil
+}
+
+func (repo *RepoMemory) findWebhook(subscriptionID int) (models.WebhookSubscription, bool) {
+	for _, subscription := range repo.webhooks {
+		if subscription.ID == subscriptionID {
+			return subscription, true
+		}
+	}
+
+	return models.WebhookSubscription{}, false
+}
+
+func (repo *RepoMemory) DeleteWebhook(ctx context.Context, subscriptionID int) (err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.findWebhook(subscriptionID); !ok {
+		return ErrWebhookNotFound
+	}
+
+	webhooks := repo.webhooks[:0]
+
+	for _, subscription := range repo.webhooks {
+		if subscription.ID != subscriptionID {
+			webhooks = append(webhooks, subscription)
+		}
+	}
+
+	repo.webhooks = webhooks
+
+	// Доставки удаляются вместе с подпиской, как по внешнему ключу.
+	deliveries := repo.deliveries[:0]
+
+	for _, stored := range repo.deliveries {
+		if stored.delivery.SubscriptionID != subscriptionID {
+			deliveries = append(deliveries, stored)
+		}
+	}
+
+	repo.deliveries = deliveries
+
+	return nil
+}
+
+func (repo *RepoMemory) FindWebhookDeliveries(ctx context.Context, subscriptionID int, limit int) (deliveries []models.WebhookDelivery, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	deliveries = make([]models.WebhookDelivery, 0)
+
+	for i := len(repo.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		stored := repo.deliveries[i]
+
+		if stored.delivery.SubscriptionID != subscriptionID {
+			continue
+		}
+
+		delivery := stored.delivery
+		delivery.NextAttemptAt = formatTime(stored.nextAttemptAt)
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (repo *RepoMemory) ReplayWebhook(ctx context.Context, subscriptionID int, fromEventID int64) (count int, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	subscription, ok := repo.findWebhook(subscriptionID)
+	if !ok {
+		return 0, ErrWebhookNotFound
+	}
+
+	for _, event := range repo.outbox {
+		if event.ID >= fromEventID && subscription.Accepts(event.Type) {
+			repo.addDelivery(subscription.ID, event)
+
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (repo *RepoMemory) EnqueueWebhookDeliveries(ctx context.Context, event models.OutboxEvent) (err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, subscription := range repo.webhooks {
+		if subscription.Accepts(event.Type) {
+			repo.addDelivery(subscription.ID, event)
+		}
+	}
+
+	return nil
+}
+
+func (repo *RepoMemory) addDelivery(subscriptionID int, event models.OutboxEvent) {
+	id := int64(1)
+	if len(repo.deliveries) > 0 {
+		id = repo.deliveries[len(repo.deliveries)-1].delivery.ID + 1
+	}
+
+	now := repo.now()
+
+	repo.deliveries = append(repo.deliveries, memoryDelivery{
+		delivery: models.WebhookDelivery{
+			ID:             id,
+			SubscriptionID: subscriptionID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			State:          models.DeliveryPending,
+			CreatedAt:      formatTime(now),
+		},
+		nextAttemptAt: now,
+	})
+}
+
+// FindDueWebhookDeliveries арендует доставки на время lease, сдвигая время
+// следующей попытки, чтобы их не взял другой обработчик.
+func (repo *RepoMemory) FindDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) (deliveries []models.WebhookDelivery, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := repo.now()
+
+	due := make([]*memoryDelivery, 0)
+
+	for i := range repo.deliveries {
+		stored := &repo.deliveries[i]
+
+		if stored.delivery.State == models.DeliveryPending && !stored.nextAttemptAt.After(now) {
+			due = append(due, stored)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].nextAttemptAt.Before(due[j].nextAttemptAt)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].delivery.ID < due[j].delivery.ID
+	})
+
+	deliveries = make([]models.WebhookDelivery, 0, len(due))
+
+	for _, stored := range due {
+		stored.nextAttemptAt = now.Add(lease)
+
+		subscription, _ := repo.findWebhook(stored.delivery.SubscriptionID)
+		event := repo.outbox[stored.delivery.EventID-1]
+
+		delivery := models.WebhookDelivery{
+			ID:             stored.delivery.ID,
+			SubscriptionID: stored.delivery.SubscriptionID,
+			EventID:        stored.delivery.EventID,
+			EventType:      stored.delivery.EventType,
+			State:          models.DeliveryPending,
+			Attempts:       stored.delivery.Attempts,
+			CreatedAt:      stored.delivery.CreatedAt,
+			URL:            subscription.URL,
+			Secret:         subscription.Secret,
+		}
+
+		delivery.Payload, err = models.MarshalOutboxEvent(event)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (repo *RepoMemory) SaveWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) (err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for i := range repo.deliveries {
+		stored := &repo.deliveries[i]
+
+		if stored.delivery.ID != delivery.ID {
+			continue
+		}
+
+		if delivery.NextAttemptAt != "" {
+			if stored.nextAttemptAt, err = time.Parse(time.RFC3339Nano, delivery.NextAttemptAt); err != nil {
+				return err
+			}
+		}
+
+		stored.delivery.State = delivery.State
+		stored.delivery.Attempts = delivery.Attempts
+		stored.delivery.LastStatusCode = delivery.LastStatusCode
+		stored.delivery.LastError = delivery.LastError
+
+		if delivery.State == models.DeliveryDelivered {
+			stored.delivery.DeliveredAt = formatTime(repo.now())
+		}
+	}
+
+	return nil
+}
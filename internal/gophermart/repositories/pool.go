@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+// PoolConfig настраивает пул соединений; нулевые значения оставляют
+// умолчания database/sql и pgx.
+type PoolConfig struct {
+	MaxOpenConns           int
+	MaxIdleConns           int
+	ConnMaxLifetime        time.Duration
+	ConnMaxIdleTime        time.Duration
+	StatementCacheCapacity int
+}
+
+// PoolStater реализуют хранилища с пулом соединений.
+type PoolStater interface {
+	PoolStats() models.PoolStats
+}
+
+func (c PoolConfig) apply(db *sql.DB) {
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+
+	if c.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+
+	if c.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+}
+
+func poolStats(db *sql.DB) models.PoolStats {
+	stats := db.Stats()
+
+	return models.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// WaitReady пингует хранилище, пока оно не ответит или не закончатся
+// попытки, чтобы сервис не стартовал без базы.
+func WaitReady(ctx context.Context, repo Repo, attempts int, interval time.Duration) (err error) {
+	for attempt := 1; ; attempt++ {
+		if err = repo.Ping(ctx); err == nil {
+			return nil
+		}
+
+		if attempt >= attempts {
+			return fmt.Errorf("database is unreachable after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -6,30 +6,41 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"errors"
-	"fmt"
-	"strings"
 
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgconn/stmtcache"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/vukit/gomac/internal/gophermart/models"
-
-	// Register pgx stdlib
-	_ "github.com/jackc/pgx/v4/stdlib"
 )
 
 type RepoPostgreSQL struct {
 	db *sql.DB
 }
 
-func NewRepositoryPostgreSQL(dsn string) (repo RepoPostgreSQL, err error) {
-	db, err := sql.Open("pgx", dsn)
-
-	repo = RepoPostgreSQL{db: db}
-
+func NewRepositoryPostgreSQL(dsn string, pool PoolConfig) (repo RepoPostgreSQL, err error) {
+	connConfig, err := pgx.ParseConfig(dsn)
 	if err != nil {
 		return repo, err
 	}
 
-	return repo, err
+	if pool.StatementCacheCapacity > 0 {
+		capacity := pool.StatementCacheCapacity
+
+		connConfig.BuildStatementCache = func(conn *pgconn.PgConn) stmtcache.Cache {
+			return stmtcache.New(conn, stmtcache.ModePrepare, capacity)
+		}
+	}
+
+	db := stdlib.OpenDB(*connConfig)
+	pool.apply(db)
+
+	return RepoPostgreSQL{db: db}, nil
+}
+
+func (repo RepoPostgreSQL) PoolStats() models.PoolStats {
+	return poolStats(repo.db)
 }
 
 func (repo RepoPostgreSQL) SaveClient(ctx context.Context, client models.Client) (clientID int, err error) {
@@ -76,33 +87,10 @@ func (repo RepoPostgreSQL) FindClient(ctx context.Context, client models.Client)
 	return clientID, err
 }
 
-func (repo RepoPostgreSQL) SaveOrder(ctx context.Context, order *models.Order) (err error) {
-	var dbClientID int
-
-	if repo.db == nil {
-		return ErrNoDBConn
-	}
-
-	err = repo.db.QueryRowContext(ctx,
-		`SELECT client_id FROM orders WHERE order_number = $1`,
-		order.Number).Scan(&dbClientID)
-
-	if err == nil {
-		if order.ClientID == dbClientID {
-			return ErrOrderNumberUploadedThisClient
-		}
+const orderColumns = `order_id, client_id, order_number, accrual, status, uploaded_at, COALESCE(amount, 0), COALESCE(store_id, '')`
 
-		return ErrOrderNumberUploadedAnotherClient
-	}
-
-	_, err = repo.db.ExecContext(ctx,
-		`INSERT INTO orders (client_id, order_number, status, uploaded_at) VALUES($1, $2, $3, now())`,
-		order.ClientID, order.Number, "NEW")
-	if err != nil {
-		return ErrOrderNumberUploadedAnotherClient
-	}
-
-	return err
+func orderFields(order *models.Order) []interface{} {
+	return []interface{}{&order.ID, &order.ClientID, &order.Number, &order.Accrual, &order.Status, &order.UploadedAt, &order.Amount, &order.StoreID}
 }
 
 func (repo RepoPostgreSQL) FindOrders(ctx context.Context, client models.Client) (orders []models.Order, err error) {
@@ -140,78 +128,66 @@ func (repo RepoPostgreSQL) FindOrders(ctx context.Context, client models.Client)
 	return orders, err
 }
 
-func (repo RepoPostgreSQL) SaveWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) (err error) {
+func (repo RepoPostgreSQL) FindOrder(ctx context.Context, client models.Client, number string) (order models.Order, err error) {
 	if repo.db == nil {
-		return ErrNoDBConn
+		return order, ErrNoDBConn
 	}
 
-	tx, err := repo.db.Begin()
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT `+orderColumns+` FROM orders WHERE client_id = $1 AND order_number = $2`,
+		client.ID, number).Scan(orderFields(&order)...)
 	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err != nil && tx != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				err = fmt.Errorf("save withdrawal: tx err %w: roll back err %v", err, rbErr)
-			}
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return order, ErrOrderNotFound
+		default:
+			return order, err
 		}
-	}()
-
-	newBalance := 0
-
-	err = tx.QueryRowContext(ctx,
-		`SELECT COALESCE((SELECT sum(accrual) FROM orders WHERE status = 'PROCESSED' AND client_id = $1), 0) - 
-				COALESCE((SELECT sum(sum) FROM withdrawals WHERE client_id = $1), 0) - $2 as balance`,
-		withdrawal.ClientID, withdrawal.Sum).Scan(&newBalance)
-	if err != nil {
-		return err
 	}
 
-	if newBalance < 0 {
-		return ErrThereAreNotEnoughAccrual
-	}
+	return order, err
+}
 
-	_, err = tx.ExecContext(ctx,
-		`INSERT INTO withdrawals (client_id, order_number, sum, processed_at) VALUES($1, $2, $3, now())`,
-		withdrawal.ClientID, withdrawal.Order, withdrawal.Sum)
-	if err != nil {
-		return err
+func (repo RepoPostgreSQL) FindOrderByID(ctx context.Context, client models.Client, id int) (order models.Order, err error) {
+	if repo.db == nil {
+		return order, ErrNoDBConn
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		return err
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT `+orderColumns+` FROM orders WHERE client_id = $1 AND order_id = $2`,
+		client.ID, id).Scan(orderFields(&order)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return order, ErrOrderNotFound
 	}
 
-	return nil
+	return order, err
 }
 
-func (repo RepoPostgreSQL) FindWithdrawals(ctx context.Context, client models.Client) (withdrawals []models.Withdrawal, err error) {
+func (repo RepoPostgreSQL) FindOrderEvents(ctx context.Context, order models.Order) (events []models.OrderEvent, err error) {
 	if repo.db == nil {
 		return nil, ErrNoDBConn
 	}
 
 	rows, err := repo.db.QueryContext(ctx,
-		"SELECT order_number, sum, processed_at FROM withdrawals WHERE client_id = $1 ORDER BY processed_at",
-		client.ID)
+		`SELECT event, COALESCE(status_from::text, ''), status_to, COALESCE(accrual, 0), created_at FROM order_events WHERE order_id = $1 ORDER BY created_at, event_id`,
+		order.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 
-	withdrawals = make([]models.Withdrawal, 0)
+	events = make([]models.OrderEvent, 0)
 
 	for rows.Next() {
-		withdrawal := models.Withdrawal{}
+		event := models.OrderEvent{}
 
-		err = rows.Scan(&withdrawal.Order, &withdrawal.Sum, &withdrawal.ProcessedAt)
+		err = rows.Scan(&event.Event, &event.StatusFrom, &event.Status, &event.Accrual, &event.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
 
-		withdrawals = append(withdrawals, withdrawal)
+		events = append(events, event)
 	}
 
 	err = rows.Err()
@@ -219,102 +195,167 @@ func (repo RepoPostgreSQL) FindWithdrawals(ctx context.Context, client models.Cl
 		return nil, err
 	}
 
-	return withdrawals, err
+	return events, err
 }
 
-func (repo RepoPostgreSQL) FindBalance(ctx context.Context, client models.Client) (balance *models.Balace, err error) {
+func (repo RepoPostgreSQL) FindOrdersByNumbers(ctx context.Context, client models.Client, numbers []string) (orders []models.Order, err error) {
 	if repo.db == nil {
 		return nil, ErrNoDBConn
 	}
 
-	balance = &models.Balace{}
-
-	accurals := float64(0)
+	values := pgtype.TextArray{}
+	if err = values.Set(numbers); err != nil {
+		return nil, err
+	}
 
-	err = repo.db.QueryRowContext(ctx,
-		`SELECT COALESCE((SELECT sum(accrual) FROM orders WHERE status = 'PROCESSED' AND client_id = $1), 0) as accruals,
-				COALESCE((SELECT sum(sum) FROM withdrawals WHERE client_id = $1), 0) as withdrawn`,
-		client.ID).Scan(&accurals, &balance.Withdrawn)
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT `+orderColumns+` FROM orders WHERE client_id = $1 AND order_number = ANY($2)`,
+		client.ID, values)
 	if err != nil {
 		return nil, err
 	}
 
-	balance.Current = accurals - balance.Withdrawn
+	defer rows.Close()
 
-	return balance, err
-}
+	orders = make([]models.Order, 0, len(numbers))
 
-func (repo RepoPostgreSQL) SaveTask(ctx context.Context, task models.Task) (err error) {
-	if repo.db == nil {
-		return ErrNoDBConn
+	for rows.Next() {
+		order := models.Order{}
+
+		err = rows.Scan(orderFields(&order)...)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
 	}
 
-	_, err = repo.db.ExecContext(ctx,
-		`UPDATE orders SET accrual = $1, status = $2 WHERE order_id = $3`,
-		task.Accrual, task.Status, task.OrderID)
+	err = rows.Err()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return err
+	return orders, err
 }
 
-func (repo RepoPostgreSQL) FindTasks(ctx context.Context, statuses ...string) (tasks []models.Task, err error) {
+// FindOrdersEvents загружает историю сразу нескольких заказов одним запросом.
+func (repo RepoPostgreSQL) FindOrdersEvents(ctx context.Context, orderIDs []int) (events map[int][]models.OrderEvent, err error) {
 	if repo.db == nil {
 		return nil, ErrNoDBConn
 	}
 
-	tx, err := repo.db.Begin()
+	ids := pgtype.Int4Array{}
+	if err = ids.Set(orderIDs); err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT order_id, event, COALESCE(status_from::text, ''), status_to, COALESCE(accrual, 0), created_at FROM order_events WHERE order_id = ANY($1) ORDER BY order_id, created_at, event_id`,
+		ids)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		if err != nil && tx != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				err = fmt.Errorf("find tasks: tx err %w: roll back err %v", err, rbErr)
-			}
+	defer rows.Close()
+
+	events = make(map[int][]models.OrderEvent, len(orderIDs))
+
+	for rows.Next() {
+		var orderID int
+
+		event := models.OrderEvent{}
+
+		err = rows.Scan(&orderID, &event.Event, &event.StatusFrom, &event.Status, &event.Accrual, &event.CreatedAt)
+		if err != nil {
+			return nil, err
 		}
-	}()
 
-	query := `SELECT order_id, order_number FROM orders WHERE status IN ('` + strings.Join(statuses, "','") + `') FOR UPDATE`
+		events[orderID] = append(events[orderID], event)
+	}
 
-	rows, err := tx.QueryContext(ctx, query)
+	err = rows.Err()
 	if err != nil {
 		return nil, err
 	}
 
-	if errRows := rows.Err(); errRows != nil {
-		return nil, errRows
+	return events, err
+}
+
+func (repo RepoPostgreSQL) FindWithdrawals(ctx context.Context, client models.Client) (withdrawals []models.Withdrawal, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		"SELECT order_number, sum, processed_at FROM withdrawals WHERE client_id = $1 ORDER BY processed_at",
+		client.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	defer rows.Close()
 
-	tasks = make([]models.Task, 0)
+	withdrawals = make([]models.Withdrawal, 0)
 
 	for rows.Next() {
-		task := models.Task{}
+		withdrawal := models.Withdrawal{}
 
-		err = rows.Scan(&task.OrderID, &task.OrderNumber)
+		err = rows.Scan(&withdrawal.Order, &withdrawal.Sum, &withdrawal.ProcessedAt)
 		if err != nil {
 			return nil, err
 		}
 
-		tasks = append(tasks, task)
+		withdrawals = append(withdrawals, withdrawal)
 	}
 
-	_, err = tx.ExecContext(ctx,
-		`UPDATE orders SET status = 'PROCESSING' WHERE status = 'NEW'`)
+	err = rows.Err()
 	if err != nil {
 		return nil, err
 	}
 
-	err = tx.Commit()
+	return withdrawals, err
+}
+
+func (repo RepoPostgreSQL) FindBalance(ctx context.Context, client models.Client) (balance *models.Balace, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	balance = &models.Balace{}
+
+	accurals := float64(0)
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT COALESCE((SELECT sum(accrual) FROM orders WHERE status = 'PROCESSED' AND client_id = $1), 0) as accruals,
+				COALESCE((SELECT sum(sum) FROM withdrawals WHERE client_id = $1), 0) as withdrawn`,
+		client.ID).Scan(&accurals, &balance.Withdrawn)
 	if err != nil {
 		return nil, err
 	}
 
-	return tasks, nil
+	balance.Current = accurals - balance.Withdrawn
+
+	return balance, err
+}
+
+func (repo RepoPostgreSQL) FindTask(ctx context.Context, orderNumber string) (task models.Task, err error) {
+	if repo.db == nil {
+		return task, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT order_id, client_id, order_number, accrual, status FROM orders WHERE order_number = $1`,
+		orderNumber).Scan(&task.OrderID, &task.ClientID, &task.OrderNumber, &task.Accrual, &task.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return task, ErrOrderNotFound
+		default:
+			return task, err
+		}
+	}
+
+	return task, err
 }
 
 func (repo RepoPostgreSQL) Close() error {
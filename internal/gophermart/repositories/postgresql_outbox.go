@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// outboxInsert берёт advisory-блокировку до конца транзакции перед выдачей
+// event_id. Иначе транзакция с меньшим event_id может зафиксироваться позже
+// транзакции с большим, реле уже сдвинет курсор дальше и событие не будет
+// доставлено. С блокировкой записи в outbox фиксируются в порядке event_id.
+const outboxInsert = `INSERT INTO outbox (client_id, event_type, payload, created_at)
+	SELECT $1::int, $2::varchar, $3::jsonb, now() FROM (SELECT pg_advisory_xact_lock(7245101)) outbox_lock`
+
+func saveOutboxEvent(ctx context.Context, tx execer, clientID int, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, outboxInsert, clientID, eventType, string(data))
+
+	return err
+}
+
+func (repo RepoPostgreSQL) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	return saveOutboxEvent(ctx, repo.db, clientID, eventType, payload)
+}
+
+func (repo RepoPostgreSQL) FindOutboxEvents(ctx context.Context, afterEventID int64, limit int) (outboxEvents []models.OutboxEvent, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT event_id, client_id, event_type, payload, created_at FROM outbox WHERE event_id > $1 ORDER BY event_id LIMIT $2`,
+		afterEventID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOutboxEvents(rows)
+}
+
+func (repo RepoPostgreSQL) FindClientOutboxEvents(ctx context.Context, clientID int, afterEventID int64, limit int) (outboxEvents []models.OutboxEvent, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT event_id, client_id, event_type, payload, created_at FROM outbox
+		WHERE client_id = $1 AND event_id > $2 ORDER BY event_id LIMIT $3`,
+		clientID, afterEventID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOutboxEvents(rows)
+}
+
+func scanOutboxEvents(rows *sql.Rows) (outboxEvents []models.OutboxEvent, err error) {
+	defer rows.Close()
+
+	outboxEvents = make([]models.OutboxEvent, 0)
+
+	for rows.Next() {
+		event := models.OutboxEvent{}
+
+		err = rows.Scan(&event.ID, &event.ClientID, &event.Type, &event.Payload, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		outboxEvents = append(outboxEvents, event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return outboxEvents, err
+}
+
+func (repo RepoPostgreSQL) FindLastOutboxEventID(ctx context.Context) (eventID int64, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT COALESCE(max(event_id), 0) FROM outbox`).Scan(&eventID)
+
+	return eventID, err
+}
+
+func (repo RepoPostgreSQL) FindOutboxCursor(ctx context.Context, sink string) (eventID int64, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT event_id FROM outbox_cursors WHERE sink = $1`,
+		sink).Scan(&eventID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrOutboxCursorNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return eventID, err
+}
+
+func (repo RepoPostgreSQL) SaveOutboxCursor(ctx context.Context, sink string, eventID int64) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`INSERT INTO outbox_cursors (sink, event_id, updated_at) VALUES($1, $2, now())
+		ON CONFLICT (sink) DO UPDATE SET event_id = GREATEST(outbox_cursors.event_id, EXCLUDED.event_id), updated_at = now()`,
+		sink, eventID)
+
+	return err
+}
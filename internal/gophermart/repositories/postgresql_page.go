@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+type pageQuery struct {
+	conditions []string
+	args       []interface{}
+
+	// timeArg преобразует время в параметр запроса; SQLite хранит время строкой.
+	timeArg func(time.Time) interface{}
+}
+
+func (q *pageQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *pageQuery) timeValue(value time.Time) interface{} {
+	if q.timeArg == nil {
+		return value
+	}
+
+	return q.timeArg(value)
+}
+
+func (q *pageQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+// build дополняет запрос фильтрами, курсором, сортировкой и лимитом; лимит
+// увеличен на единицу, чтобы узнать, есть ли следующая страница.
+func (q *pageQuery) build(query models.ListQuery, selectSQL, timeColumn, idColumn string) string {
+	if !query.From.IsZero() {
+		q.where(timeColumn + " >= " + q.arg(q.timeValue(query.From)))
+	}
+
+	if !query.To.IsZero() {
+		q.where(timeColumn + " < " + q.arg(q.timeValue(query.To)))
+	}
+
+	direction, compare := "ASC", ">"
+	if query.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if query.After != nil {
+		q.where(fmt.Sprintf("(%s, %s) %s (%s, %s)", timeColumn, idColumn, compare, q.arg(q.timeValue(query.After.At)), q.arg(query.After.ID)))
+	}
+
+	return fmt.Sprintf("%s WHERE %s ORDER BY %s %s, %s %s LIMIT %s",
+		selectSQL, strings.Join(q.conditions, " AND "),
+		timeColumn, direction, idColumn, direction, q.arg(query.Limit+1))
+}
+
+func nextCursor(at string, id int) (*models.Cursor, error) {
+	parsed, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Cursor{At: parsed, ID: id}, nil
+}
+
+func (repo RepoPostgreSQL) FindOrdersPage(ctx context.Context, client models.Client, query models.ListQuery) (page models.OrdersPage, err error) {
+	if repo.db == nil {
+		return page, ErrNoDBConn
+	}
+
+	q := &pageQuery{}
+	q.where("client_id = " + q.arg(client.ID))
+
+	if len(query.Statuses) > 0 {
+		statuses := pgtype.TextArray{}
+		if err = statuses.Set(query.Statuses); err != nil {
+			return page, err
+		}
+
+		q.where("status = ANY(" + q.arg(statuses) + "::text[]::order_status[])")
+	}
+
+	if query.MinAccrual > 0 {
+		q.where("accrual >= " + q.arg(query.MinAccrual))
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		q.build(query, "SELECT "+orderColumns+" FROM orders", "uploaded_at", "order_id"),
+		q.args...)
+	if err != nil {
+		return page, err
+	}
+
+	defer rows.Close()
+
+	page.Orders = make([]models.Order, 0, query.Limit)
+
+	for rows.Next() {
+		order := models.Order{}
+
+		err = rows.Scan(orderFields(&order)...)
+		if err != nil {
+			return page, err
+		}
+
+		page.Orders = append(page.Orders, order)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return page, err
+	}
+
+	if len(page.Orders) > query.Limit {
+		page.Orders = page.Orders[:query.Limit]
+		last := page.Orders[query.Limit-1]
+		page.Next, err = nextCursor(last.UploadedAt, last.ID)
+	}
+
+	return page, err
+}
+
+func (repo RepoPostgreSQL) FindWithdrawalsPage(ctx context.Context, client models.Client, query models.ListQuery) (page models.WithdrawalsPage, err error) {
+	if repo.db == nil {
+		return page, ErrNoDBConn
+	}
+
+	q := &pageQuery{}
+	q.where("client_id = " + q.arg(client.ID))
+
+	rows, err := repo.db.QueryContext(ctx,
+		q.build(query, "SELECT withdrawal_id, order_number, sum, processed_at FROM withdrawals", "processed_at", "withdrawal_id"),
+		q.args...)
+	if err != nil {
+		return page, err
+	}
+
+	defer rows.Close()
+
+	page.Withdrawals = make([]models.Withdrawal, 0, query.Limit)
+
+	for rows.Next() {
+		withdrawal := models.Withdrawal{ClientID: client.ID}
+
+		err = rows.Scan(&withdrawal.ID, &withdrawal.Order, &withdrawal.Sum, &withdrawal.ProcessedAt)
+		if err != nil {
+			return page, err
+		}
+
+		page.Withdrawals = append(page.Withdrawals, withdrawal)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return page, err
+	}
+
+	if len(page.Withdrawals) > query.Limit {
+		page.Withdrawals = page.Withdrawals[:query.Limit]
+		last := page.Withdrawals[query.Limit-1]
+		page.Next, err = nextCursor(last.ProcessedAt, last.ID)
+	}
+
+	return page, err
+}
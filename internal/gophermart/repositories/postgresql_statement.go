@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+// ledgerSQL — все операции по бонусному счёту: начисления датируются
+// переходом заказа в PROCESSED, списания — временем списания.
+const ledgerSQL = `
+	SELECT o.client_id, COALESCE(
+			(SELECT max(e.created_at) FROM order_events e WHERE e.order_id = o.order_id AND e.status_to = 'PROCESSED'),
+			o.uploaded_at) AS at,
+		'accrual' AS kind, o.order_number, o.accrual AS amount, o.order_id AS id
+	FROM orders o WHERE o.status = 'PROCESSED' AND o.accrual > 0
+	UNION ALL
+	SELECT w.client_id, w.processed_at, 'withdrawal', w.order_number, -w.sum, w.withdrawal_id
+	FROM withdrawals w`
+
+// statementChunk — сколько строк выписки SQLite и память отдают за один
+// проход, не удерживая соединение или блокировку на время вызова fn.
+const statementChunk = 100
+
+// Баланс считается оконной функцией по всей истории клиента, поэтому первая
+// строка выписки уже учитывает операции до начала периода.
+const statementSQL = `
+SELECT at, kind, order_number, amount, balance FROM (
+	SELECT at, kind, order_number, amount, id, sum(amount) OVER (ORDER BY at, kind, id) AS balance
+	FROM (` + ledgerSQL + `) ledger WHERE client_id = $1
+) statement
+WHERE ($2::timestamptz IS NULL OR at >= $2) AND ($3::timestamptz IS NULL OR at < $3)
+ORDER BY at, kind, id`
+
+const generateStatementsSQL = `
+INSERT INTO statements (client_id, period, opening_balance, accruals, withdrawals, closing_balance, created_at)
+SELECT client_id, $1::date,
+	COALESCE(sum(amount) FILTER (WHERE at < $2), 0),
+	COALESCE(sum(amount) FILTER (WHERE at >= $2 AND kind = 'accrual'), 0),
+	COALESCE(-sum(amount) FILTER (WHERE at >= $2 AND kind = 'withdrawal'), 0),
+	COALESCE(sum(amount), 0),
+	now()
+FROM (` + ledgerSQL + `) ledger WHERE at < $3
+GROUP BY client_id
+ON CONFLICT (client_id, period) DO NOTHING`
+
+const balanceAtSQL = `
+SELECT COALESCE(sum(amount), 0), COALESCE(-sum(amount) FILTER (WHERE kind = 'withdrawal'), 0)
+FROM (` + ledgerSQL + `) ledger WHERE client_id = $1 AND at <= $2`
+
+const selectStatementSQL = `SELECT statement_id, client_id, to_char(period, 'YYYY-MM'),
+	opening_balance, accruals, withdrawals, closing_balance, created_at FROM statements`
+
+func (repo RepoPostgreSQL) StreamStatement(ctx context.Context, client models.Client, from, to time.Time, fn func(models.StatementEntry) error) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx, statementSQL, client.ID, nullTime(from), nullTime(to))
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := models.StatementEntry{}
+
+		err = rows.Scan(&entry.At, &entry.Type, &entry.Order, &entry.Amount, &entry.Balance)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (repo RepoPostgreSQL) FindBalanceAt(ctx context.Context, client models.Client, at time.Time) (balance *models.Balace, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	balance = &models.Balace{}
+
+	err = repo.db.QueryRowContext(ctx, balanceAtSQL, client.ID, at).Scan(&balance.Current, &balance.Withdrawn)
+	if err != nil {
+		return nil, err
+	}
+
+	return balance, err
+}
+
+func (repo RepoPostgreSQL) GenerateStatements(ctx context.Context, period time.Time) (count int, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	result, err := repo.db.ExecContext(ctx, generateStatementsSQL,
+		period.Format("2006-01-02"), period, period.AddDate(0, 1, 0))
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	return int(affected), err
+}
+
+func (repo RepoPostgreSQL) FindStatements(ctx context.Context, client models.Client) (statements []models.MonthlyStatement, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		selectStatementSQL+` WHERE client_id = $1 ORDER BY period`,
+		client.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanStatements(rows)
+}
+
+func (repo RepoPostgreSQL) FindStatement(ctx context.Context, client models.Client, period time.Time) (statement models.MonthlyStatement, err error) {
+	if repo.db == nil {
+		return statement, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		selectStatementSQL+` WHERE client_id = $1 AND period = $2::date`,
+		client.ID, period.Format("2006-01-02")).Scan(&statement.ID, &statement.ClientID, &statement.Period,
+		&statement.OpeningBalance, &statement.Accruals, &statement.Withdrawals, &statement.ClosingBalance, &statement.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return statement, ErrStatementNotFound
+	}
+
+	return statement, err
+}
+
+func (repo RepoPostgreSQL) FindUnnotifiedStatements(ctx context.Context, limit int) (statements []models.MonthlyStatement, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		selectStatementSQL+` WHERE notified_at IS NULL ORDER BY statement_id LIMIT $1`,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanStatements(rows)
+}
+
+func (repo RepoPostgreSQL) MarkStatementNotified(ctx context.Context, id int) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`UPDATE statements SET notified_at = now() WHERE statement_id = $1`,
+		id)
+
+	return err
+}
+
+func scanStatements(rows *sql.Rows) (statements []models.MonthlyStatement, err error) {
+	defer rows.Close()
+
+	statements = make([]models.MonthlyStatement, 0)
+
+	for rows.Next() {
+		statement := models.MonthlyStatement{}
+
+		err = rows.Scan(&statement.ID, &statement.ClientID, &statement.Period,
+			&statement.OpeningBalance, &statement.Accruals, &statement.Withdrawals, &statement.ClosingBalance, &statement.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		statements = append(statements, statement)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return statements, err
+}
+
+func nullTime(value time.Time) interface{} {
+	if value.IsZero() {
+		return nil
+	}
+
+	return value
+}
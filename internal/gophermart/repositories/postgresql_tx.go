@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgtype"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+type txPostgreSQL struct {
+	tx *sql.Tx
+}
+
+func (repo RepoPostgreSQL) InTx(ctx context.Context, fn func(Tx) error) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil && tx != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("tx err %w: roll back err %v", err, rbErr)
+			}
+		}
+	}()
+
+	if err = fn(txPostgreSQL{tx: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r txPostgreSQL) InsertOrder(ctx context.Context, order *models.Order) error {
+	err := r.tx.QueryRowContext(ctx,
+		`INSERT INTO orders (client_id, order_number, status, uploaded_at, amount, store_id) VALUES($1, $2, $3, now(), NULLIF($4::double precision, 0), NULLIF($5::varchar, ''))
+		ON CONFLICT (order_number) DO NOTHING RETURNING order_id, uploaded_at`,
+		order.ClientID, order.Number, models.StatusNew, order.Amount, order.StoreID).Scan(&order.ID, &order.UploadedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		var dbClientID int
+
+		err = r.tx.QueryRowContext(ctx,
+			`SELECT client_id FROM orders WHERE order_number = $1`,
+			order.Number).Scan(&dbClientID)
+		if err != nil {
+			return err
+		}
+
+		if order.ClientID == dbClientID {
+			return ErrOrderNumberUploadedThisClient
+		}
+
+		return ErrOrderNumberUploadedAnotherClient
+	}
+
+	if err != nil {
+		return err
+	}
+
+	order.Status = models.StatusNew
+
+	return nil
+}
+
+func (r txPostgreSQL) SaveOrderEvent(ctx context.Context, orderID int, event models.OrderEvent) error {
+	_, err := r.tx.ExecContext(ctx,
+		`INSERT INTO order_events (order_id, event, status_from, status_to, accrual, created_at)
+		VALUES($1, $2, NULLIF($3::text, '')::order_status, $4, $5, now())`,
+		orderID, event.Event, event.StatusFrom, event.Status, event.Accrual)
+
+	return err
+}
+
+func (r txPostgreSQL) FindBalanceForUpdate(ctx context.Context, client models.Client) (*models.Balace, error) {
+	_, err := r.tx.ExecContext(ctx,
+		`SELECT client_id FROM clients WHERE client_id = $1 FOR UPDATE`,
+		client.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &models.Balace{}
+
+	accurals := float64(0)
+
+	err = r.tx.QueryRowContext(ctx,
+		`SELECT COALESCE((SELECT sum(accrual) FROM orders WHERE status = 'PROCESSED' AND client_id = $1), 0) as accruals,
+				COALESCE((SELECT sum(sum) FROM withdrawals WHERE client_id = $1), 0) as withdrawn`,
+		client.ID).Scan(&accurals, &balance.Withdrawn)
+	if err != nil {
+		return nil, err
+	}
+
+	balance.Current = accurals - balance.Withdrawn
+
+	return balance, nil
+}
+
+func (r txPostgreSQL) InsertWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	return r.tx.QueryRowContext(ctx,
+		`INSERT INTO withdrawals (client_id, order_number, sum, processed_at) VALUES($1, $2, $3, now()) RETURNING withdrawal_id, processed_at`,
+		withdrawal.ClientID, withdrawal.Order, withdrawal.Sum).Scan(&withdrawal.ID, &withdrawal.ProcessedAt)
+}
+
+func (r txPostgreSQL) FindTaskForUpdate(ctx context.Context, orderNumber string) (task models.Task, err error) {
+	err = r.tx.QueryRowContext(ctx,
+		`SELECT order_id, client_id, order_number, accrual, status FROM orders WHERE order_number = $1 FOR UPDATE`,
+		orderNumber).Scan(&task.OrderID, &task.ClientID, &task.OrderNumber, &task.Accrual, &task.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return task, ErrOrderNotFound
+	}
+
+	return task, err
+}
+
+func (r txPostgreSQL) UpdateTask(ctx context.Context, task models.Task) error {
+	_, err := r.tx.ExecContext(ctx,
+		`UPDATE orders SET accrual = $1, status = $2 WHERE order_id = $3`,
+		task.Accrual, task.Status, task.OrderID)
+
+	return err
+}
+
+func (r txPostgreSQL) FindTasks(ctx context.Context, statuses ...models.OrderStatus) (tasks []models.Task, err error) {
+	statusArray := pgtype.TextArray{}
+	if err = statusArray.Set(statuses); err != nil {
+		return nil, err
+	}
+
+	// Приводится параметр, а не колонка, чтобы работал индекс по статусу:
+	// FindTasks вызывается каждую секунду. Массив передаётся как text[],
+	// потому что pgx не знает бинарного формата order_status[].
+	rows, err := r.tx.QueryContext(ctx,
+		`SELECT order_id, client_id, order_number FROM orders WHERE status = ANY($1::text[]::order_status[]) FOR UPDATE`,
+		statusArray)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tasks = make([]models.Task, 0)
+
+	for rows.Next() {
+		task := models.Task{}
+
+		err = rows.Scan(&task.OrderID, &task.ClientID, &task.OrderNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (r txPostgreSQL) PickNewTasks(ctx context.Context) (tasks []models.Task, err error) {
+	rows, err := r.tx.QueryContext(ctx,
+		`WITH picked AS (UPDATE orders SET status = 'PROCESSING' WHERE status = 'NEW' RETURNING order_id, client_id, order_number, accrual)
+		SELECT order_id, client_id, order_number, COALESCE(accrual, 0) FROM picked ORDER BY order_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPickedTasks(rows)
+}
+
+// scanPickedTasks читает заказы, взятые в обработку, целиком: в транзакции
+// нельзя выполнять запросы, пока открыт курсор.
+func scanPickedTasks(rows *sql.Rows) (tasks []models.Task, err error) {
+	defer rows.Close()
+
+	tasks = make([]models.Task, 0)
+
+	for rows.Next() {
+		task := models.Task{Status: models.StatusProcessing}
+
+		err = rows.Scan(&task.OrderID, &task.ClientID, &task.OrderNumber, &task.Accrual)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (r txPostgreSQL) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) error {
+	return saveOutboxEvent(ctx, r.tx, clientID, eventType, payload)
+}
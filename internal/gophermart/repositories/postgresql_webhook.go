@@ -0,0 +1,273 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func (repo RepoPostgreSQL) SaveWebhook(ctx context.Context, subscription *models.WebhookSubscription) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	eventTypes := pgtype.TextArray{}
+	if err = eventTypes.Set(append([]string{}, subscription.EventTypes...)); err != nil {
+		return err
+	}
+
+	return repo.db.QueryRowContext(ctx,
+		`INSERT INTO webhook_subscriptions (url, secret, event_types, created_at) VALUES($1, $2, $3, now()) RETURNING subscription_id, created_at`,
+		subscription.URL, subscription.Secret, eventTypes).Scan(&subscription.ID, &subscription.CreatedAt)
+}
+
+func (repo RepoPostgreSQL) FindWebhooks(ctx context.Context) (subscriptions []models.WebhookSubscription, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT subscription_id, url, secret, event_types, created_at FROM webhook_subscriptions ORDER BY subscription_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	subscriptions = make([]models.WebhookSubscription, 0)
+
+	for rows.Next() {
+		subscription := models.WebhookSubscription{}
+		eventTypes := pgtype.TextArray{}
+
+		err = rows.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes, &subscription.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = eventTypes.AssignTo(&subscription.EventTypes); err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, err
+}
+
+func (repo RepoPostgreSQL) DeleteWebhook(ctx context.Context, subscriptionID int) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	result, err := repo.db.ExecContext(ctx,
+		`DELETE FROM webhook_subscriptions WHERE subscription_id = $1`,
+		subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (repo RepoPostgreSQL) FindWebhookDeliveries(ctx context.Context, subscriptionID int, limit int) (deliveries []models.WebhookDelivery, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT d.delivery_id, d.subscription_id, d.event_id, o.event_type, d.state, d.attempts,
+				COALESCE(d.last_status_code, 0), COALESCE(d.last_error, ''), d.next_attempt_at, d.delivered_at, d.created_at
+		FROM webhook_deliveries d JOIN outbox o ON o.event_id = d.event_id
+		WHERE d.subscription_id = $1 ORDER BY d.delivery_id DESC LIMIT $2`,
+		subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries = make([]models.WebhookDelivery, 0)
+
+	for rows.Next() {
+		delivery := models.WebhookDelivery{}
+		deliveredAt := sql.NullString{}
+
+		err = rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.State,
+			&delivery.Attempts, &delivery.LastStatusCode, &delivery.LastError, &delivery.NextAttemptAt,
+			&deliveredAt, &delivery.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		delivery.DeliveredAt = deliveredAt.String
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, err
+}
+
+func (repo RepoPostgreSQL) ReplayWebhook(ctx context.Context, subscriptionID int, fromEventID int64) (count int, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil && tx != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("replay webhook: tx err %w: roll back err %v", err, rbErr)
+			}
+		}
+	}()
+
+	var eventTypes pgtype.TextArray
+
+	err = tx.QueryRowContext(ctx,
+		`SELECT event_types FROM webhook_subscriptions WHERE subscription_id = $1`,
+		subscriptionID).Scan(&eventTypes)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrWebhookNotFound
+		}
+
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (subscription_id, event_id, next_attempt_at, created_at)
+		SELECT $1, event_id, now(), now() FROM outbox
+		WHERE event_id >= $2 AND (cardinality($3::text[]) = 0 OR event_type = ANY($3::text[]))`,
+		subscriptionID, fromEventID, eventTypes)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), tx.Commit()
+}
+
+func (repo RepoPostgreSQL) EnqueueWebhookDeliveries(ctx context.Context, event models.OutboxEvent) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (subscription_id, event_id, next_attempt_at, created_at)
+		SELECT subscription_id, $1, now(), now() FROM webhook_subscriptions
+		WHERE cardinality(event_types) = 0 OR $2 = ANY(event_types)`,
+		event.ID, event.Type)
+
+	return err
+}
+
+func (repo RepoPostgreSQL) FindDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) (deliveries []models.WebhookDelivery, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`WITH due AS (
+			SELECT delivery_id FROM webhook_deliveries
+			WHERE state = 'PENDING' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at LIMIT $1 FOR UPDATE SKIP LOCKED
+		), leased AS (
+			UPDATE webhook_deliveries d SET next_attempt_at = now() + $2::bigint * interval '1 millisecond'
+			FROM due WHERE d.delivery_id = due.delivery_id
+			RETURNING d.delivery_id, d.subscription_id, d.event_id, d.attempts, d.created_at
+		)
+		SELECT l.delivery_id, l.subscription_id, l.event_id, o.event_type, l.attempts, l.created_at,
+				s.url, s.secret, o.client_id, o.payload, o.created_at
+		FROM leased l
+		JOIN webhook_subscriptions s ON s.subscription_id = l.subscription_id
+		JOIN outbox o ON o.event_id = l.event_id
+		ORDER BY l.delivery_id`,
+		limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries = make([]models.WebhookDelivery, 0)
+
+	for rows.Next() {
+		var (
+			delivery = models.WebhookDelivery{State: models.DeliveryPending}
+			event    = models.OutboxEvent{}
+			payload  []byte
+		)
+
+		err = rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Attempts,
+			&delivery.CreatedAt, &delivery.URL, &delivery.Secret, &event.ClientID, &payload, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		event.ID = delivery.EventID
+		event.Type = delivery.EventType
+		event.Payload = payload
+
+		delivery.Payload, err = models.MarshalOutboxEvent(event)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, err
+}
+
+func (repo RepoPostgreSQL) SaveWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET state = $1, attempts = $2, last_status_code = NULLIF($3, 0), last_error = NULLIF($4, ''),
+				next_attempt_at = COALESCE(NULLIF($5, '')::timestamptz, next_attempt_at),
+				delivered_at = CASE WHEN $1 = 'DELIVERED' THEN now() ELSE delivered_at END
+		WHERE delivery_id = $6`,
+		delivery.State, delivery.Attempts, delivery.LastStatusCode, delivery.LastError, delivery.NextAttemptAt, delivery.ID)
+
+	return err
+}
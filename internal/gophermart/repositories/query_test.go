@@ -0,0 +1,353 @@
+package repositories_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryArgument — номер аргумента с текстом запроса у методов database/sql.
+var queryArgument = map[string]int{
+	"Exec":            0,
+	"Query":           0,
+	"QueryRow":        0,
+	"Prepare":         0,
+	"ExecContext":     1,
+	"QueryContext":    1,
+	"QueryRowContext": 1,
+	"PrepareContext":  1,
+}
+
+// TestStaticQueries проверяет, что текст каждого запроса репозиториев
+// собран из строковых литералов и констант пакета: значения попадают в
+// запрос только параметрами. Условия pageQuery проверяются там, где они
+// добавляются, а q.arg возвращает лишь номер параметра; arg и build
+// разрешены только у переменных типа pageQuery.
+func TestStaticQueries(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	parsed := make([]*ast.File, 0, len(files))
+
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+
+		parsed = append(parsed, file)
+	}
+
+	consts := packageConsts(parsed)
+	constructors := pageQueryConstructors(parsed)
+
+	for _, file := range parsed {
+		for _, violation := range queryViolations(fset, file, consts, constructors) {
+			t.Errorf("query is built from runtime values: %s", violation)
+		}
+	}
+}
+
+func TestQueryViolations(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{
+			name: "case 1",
+			body: "db.QueryContext(ctx, `SELECT 1 FROM orders WHERE status = $1`, status)",
+			want: 0,
+		},
+		{
+			name: "case 2",
+			body: `db.QueryRowContext(ctx, "SELECT " + columns + " FROM orders")`,
+			want: 0,
+		},
+		{
+			name: "case 3",
+			body: `db.Exec("UPDATE orders SET status = 'NEW'")`,
+			want: 0,
+		},
+		{
+			name: "case 4",
+			body: `q := &pageQuery{}; q.where("client_id = " + q.arg(id)); db.QueryContext(ctx, q.build(query, "SELECT 1 FROM orders", "uploaded_at", "order_id"), q.args...)`,
+			want: 0,
+		},
+		{
+			name: "case 5",
+			body: "db.QueryContext(ctx, `SELECT 1 FROM orders WHERE status IN ('` + strings.Join(statuses, \"','\") + `')`)",
+			want: 1,
+		},
+		{
+			name: "case 6",
+			body: `query := "SELECT 1"; db.QueryContext(ctx, query)`,
+			want: 1,
+		},
+		{
+			name: "case 7",
+			body: `db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", table))`,
+			want: 1,
+		},
+		{
+			name: "case 8",
+			body: `q.where("status = '" + status + "'")`,
+			want: 1,
+		},
+		{
+			name: "case 9",
+			body: `q := newPageQuery(); db.QueryContext(ctx, q.build(query, "SELECT 1 FROM " + table, "uploaded_at", "order_id"))`,
+			want: 1,
+		},
+		{
+			name: "case 10",
+			body: `db.QueryContext(ctx, sb.build())`,
+			want: 1,
+		},
+		{
+			name: "case 11",
+			body: `q := newPageQuery(); db.QueryContext(ctx, "SELECT 1 FROM orders WHERE " + q.arg(id) + " = client_id")`,
+			want: 0,
+		},
+		{
+			name: "case 12",
+			body: `db.QueryContext(ctx, "SELECT 1 FROM orders WHERE client_id = " + filter.arg(id))`,
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "package repositories\n\nconst columns = `order_id`\n\ntype pageQuery struct{}\n\n" +
+				"func newPageQuery() *pageQuery { return &pageQuery{} }\n\nfunc f() {\n" + tt.body + "\n}\n"
+
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "query.go", src, 0)
+			require.NoError(t, err)
+
+			files := []*ast.File{file}
+			violations := queryViolations(fset, file, packageConsts(files), pageQueryConstructors(files))
+
+			assert.Len(t, violations, tt.want, violations)
+		})
+	}
+}
+
+func packageConsts(files []*ast.File) map[string]bool {
+	consts := make(map[string]bool)
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					consts[name.Name] = true
+				}
+			}
+		}
+	}
+
+	return consts
+}
+
+// pageQueryConstructors возвращает функции пакета, которые создают pageQuery.
+func pageQueryConstructors(files []*ast.File) map[string]bool {
+	constructors := make(map[string]bool)
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
+				continue
+			}
+
+			if isPageQueryType(fn.Type.Results.List[0].Type) {
+				constructors[fn.Name.Name] = true
+			}
+		}
+	}
+
+	return constructors
+}
+
+func isPageQueryType(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	ident, ok := expr.(*ast.Ident)
+
+	return ok && ident.Name == "pageQuery"
+}
+
+// pageQueryVars возвращает переменные функции, в которых лежит pageQuery:
+// получатель и параметры этого типа, а также значения из литерала или
+// конструктора.
+func pageQueryVars(decl *ast.FuncDecl, constructors map[string]bool) map[string]bool {
+	vars := make(map[string]bool)
+
+	for _, fields := range []*ast.FieldList{decl.Recv, decl.Type.Params} {
+		if fields == nil {
+			continue
+		}
+
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				if isPageQueryType(field.Type) {
+					vars[name.Name] = true
+				}
+			}
+		}
+	}
+
+	if decl.Body == nil {
+		return vars
+	}
+
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+
+			for i, lhs := range node.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && isPageQueryValue(node.Rhs[i], constructors) {
+					vars[ident.Name] = true
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				if (node.Type != nil && isPageQueryType(node.Type)) || (i < len(node.Values) && isPageQueryValue(node.Values[i], constructors)) {
+					vars[name.Name] = true
+				}
+			}
+		}
+
+		return true
+	})
+
+	return vars
+}
+
+func isPageQueryValue(expr ast.Expr, constructors map[string]bool) bool {
+	switch expr := expr.(type) {
+	case *ast.UnaryExpr:
+		return expr.Op == token.AND && isPageQueryValue(expr.X, constructors)
+	case *ast.CompositeLit:
+		return isPageQueryType(expr.Type)
+	case *ast.CallExpr:
+		ident, ok := expr.Fun.(*ast.Ident)
+
+		return ok && constructors[ident.Name]
+	}
+
+	return false
+}
+
+func queryViolations(fset *token.FileSet, file *ast.File, consts, constructors map[string]bool) (violations []string) {
+	for _, decl := range file.Decls {
+		vars := map[string]bool{}
+
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			// Сам build собирает запрос из своих аргументов, а они проверяются
+			// в местах вызова.
+			if fn.Recv != nil && fn.Name.Name == "build" && isPageQueryType(fn.Recv.List[0].Type) {
+				continue
+			}
+
+			vars = pageQueryVars(fn, constructors)
+		}
+
+		violations = append(violations, declViolations(fset, decl, consts, vars)...)
+	}
+
+	return violations
+}
+
+func declViolations(fset *token.FileSet, decl ast.Decl, consts, vars map[string]bool) (violations []string) {
+	ast.Inspect(decl, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		var checked []ast.Expr
+
+		if index, ok := queryArgument[selector.Sel.Name]; ok && len(call.Args) > index {
+			checked = call.Args[index : index+1]
+		}
+
+		// Аргументы pageQuery.where и pageQuery.build попадают в текст запроса.
+		switch selector.Sel.Name {
+		case "where":
+			checked = call.Args
+		case "build":
+			if len(call.Args) > 1 {
+				checked = call.Args[1:]
+			}
+		}
+
+		for _, arg := range checked {
+			if !isStaticQuery(arg, consts, vars) {
+				violations = append(violations, fset.Position(arg.Pos()).String())
+			}
+		}
+
+		return true
+	})
+
+	return violations
+}
+
+// isStaticQuery разрешает вызовы arg и build только у переменных из vars,
+// то есть у pageQuery: одноимённые методы других типов ничего не гарантируют.
+func isStaticQuery(expr ast.Expr, consts, vars map[string]bool) bool {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		return expr.Kind == token.STRING
+	case *ast.Ident:
+		return consts[expr.Name]
+	case *ast.ParenExpr:
+		return isStaticQuery(expr.X, consts, vars)
+	case *ast.BinaryExpr:
+		return expr.Op == token.ADD && isStaticQuery(expr.X, consts, vars) && isStaticQuery(expr.Y, consts, vars)
+	case *ast.CallExpr:
+		selector, ok := expr.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+
+		receiver, ok := selector.X.(*ast.Ident)
+		if !ok || !vars[receiver.Name] {
+			return false
+		}
+
+		switch selector.Sel.Name {
+		case "arg":
+			return true
+		case "build":
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,6 +3,9 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/vukit/gomac/internal/gophermart/models"
 )
@@ -14,23 +17,114 @@ var (
 	ErrOrderNumberUploadedThisClient    = errors.New("order number has been uploaded by this client")
 	ErrOrderNumberUploadedAnotherClient = errors.New("order number has been uploaded by another client")
 	ErrThereAreNotEnoughAccrual         = errors.New("there are not enough accrual")
+	ErrOrderNotFound                    = errors.New("order not found")
+	ErrWebhookNotFound                  = errors.New("webhook subscription not found")
+	ErrOutboxCursorNotFound             = errors.New("outbox cursor not found")
+	ErrStatementNotFound                = errors.New("statement not found")
+	ErrUnknownStorage                   = errors.New("unknown storage")
 )
 
+type WebhookRepo interface {
+	SaveWebhook(context.Context, *models.WebhookSubscription) (err error)
+	FindWebhooks(context.Context) (subscriptions []models.WebhookSubscription, err error)
+	DeleteWebhook(context.Context, int) (err error)
+	FindWebhookDeliveries(ctx context.Context, subscriptionID int, limit int) (deliveries []models.WebhookDelivery, err error)
+	ReplayWebhook(ctx context.Context, subscriptionID int, fromEventID int64) (count int, err error)
+
+	EnqueueWebhookDeliveries(context.Context, models.OutboxEvent) (err error)
+	FindDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) (deliveries []models.WebhookDelivery, err error)
+	SaveWebhookDelivery(context.Context, models.WebhookDelivery) (err error)
+}
+
+type OutboxRepo interface {
+	FindOutboxEvents(ctx context.Context, afterEventID int64, limit int) (outboxEvents []models.OutboxEvent, err error)
+	FindClientOutboxEvents(ctx context.Context, clientID int, afterEventID int64, limit int) (outboxEvents []models.OutboxEvent, err error)
+	FindLastOutboxEventID(context.Context) (eventID int64, err error)
+	FindOutboxCursor(ctx context.Context, sink string) (eventID int64, err error)
+	SaveOutboxCursor(ctx context.Context, sink string, eventID int64) (err error)
+	SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) (err error)
+}
+
+type StatementRepo interface {
+	GenerateStatements(ctx context.Context, period time.Time) (count int, err error)
+	FindStatements(context.Context, models.Client) (statements []models.MonthlyStatement, err error)
+	FindStatement(ctx context.Context, client models.Client, period time.Time) (statement models.MonthlyStatement, err error)
+	FindUnnotifiedStatements(ctx context.Context, limit int) (statements []models.MonthlyStatement, err error)
+	MarkStatementNotified(ctx context.Context, id int) (err error)
+}
+
+// Tx — операции хранилища внутри одной транзакции. Правила предметной
+// области, история заказов и события outbox задают сервисы, а Tx лишь
+// выполняет запросы и блокирует строки, которые сервис собирается менять.
+type Tx interface {
+	// InsertOrder возвращает ErrOrderNumberUploadedThisClient или
+	// ErrOrderNumberUploadedAnotherClient, если номер уже загружен.
+	InsertOrder(context.Context, *models.Order) (err error)
+	SaveOrderEvent(ctx context.Context, orderID int, event models.OrderEvent) (err error)
+
+	// FindBalanceForUpdate блокирует клиента до конца транзакции, чтобы
+	// параллельные списания не увели баланс в минус.
+	FindBalanceForUpdate(context.Context, models.Client) (balance *models.Balace, err error)
+	InsertWithdrawal(context.Context, *models.Withdrawal) (err error)
+
+	FindTaskForUpdate(ctx context.Context, orderNumber string) (task models.Task, err error)
+	UpdateTask(context.Context, models.Task) (err error)
+	FindTasks(context.Context, ...models.OrderStatus) (tasks []models.Task, err error)
+	// PickNewTasks переводит все новые заказы в обработку и возвращает их.
+	PickNewTasks(context.Context) (tasks []models.Task, err error)
+
+	SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) (err error)
+}
+
 type Repo interface {
+	WebhookRepo
+	OutboxRepo
+	StatementRepo
+
 	SaveClient(context.Context, models.Client) (id int, err error)
 	FindClient(context.Context, models.Client) (id int, err error)
 
-	SaveOrder(context.Context, *models.Order) (err error)
 	FindOrders(context.Context, models.Client) (orders []models.Order, err error)
+	FindOrdersPage(context.Context, models.Client, models.ListQuery) (page models.OrdersPage, err error)
+	FindOrder(context.Context, models.Client, string) (order models.Order, err error)
+	FindOrderByID(context.Context, models.Client, int) (order models.Order, err error)
+	FindOrderEvents(context.Context, models.Order) (events []models.OrderEvent, err error)
+	FindOrdersByNumbers(context.Context, models.Client, []string) (orders []models.Order, err error)
+	FindOrdersEvents(ctx context.Context, orderIDs []int) (events map[int][]models.OrderEvent, err error)
 
-	SaveWithdrawal(context.Context, *models.Withdrawal) (err error)
 	FindWithdrawals(context.Context, models.Client) (withdrawals []models.Withdrawal, err error)
+	FindWithdrawalsPage(context.Context, models.Client, models.ListQuery) (page models.WithdrawalsPage, err error)
+	StreamStatement(ctx context.Context, client models.Client, from, to time.Time, fn func(models.StatementEntry) error) (err error)
 
 	FindBalance(context.Context, models.Client) (balance *models.Balace, err error)
+	FindBalanceAt(ctx context.Context, client models.Client, at time.Time) (balance *models.Balace, err error)
 
-	SaveTask(context.Context, models.Task) (err error)
-	FindTasks(context.Context, ...string) (tasks []models.Task, err error)
+	FindTask(context.Context, string) (task models.Task, err error)
+
+	// InTx выполняет fn в транзакции: ошибка fn откатывает все её изменения.
+	InTx(ctx context.Context, fn func(Tx) error) (err error)
 
 	Ping(context.Context) error
 	Close() error
 }
+
+// NewRepository выбирает хранилище по схеме DATABASE_URI: memory:// хранит
+// данные в памяти процесса, sqlite:// — в файле SQLite, остальные адреса
+// обслуживает PostgreSQL с настройками пула pool.
+func NewRepository(uri string, pool PoolConfig) (Repo, error) {
+	endpoint, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	switch endpoint.Scheme {
+	case "memory":
+		return NewRepositoryMemory(), nil
+	case "sqlite":
+		return NewRepositorySQLite(uri)
+	case "", "postgres", "postgresql":
+		return NewRepositoryPostgreSQL(uri, pool)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStorage, endpoint.Scheme)
+	}
+}
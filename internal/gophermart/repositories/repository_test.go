@@ -1,9 +1,73 @@
 package repositories_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/migrations"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/repositories/repotest"
+	"github.com/vukit/gomac/internal/gophermart/utils"
 )
 
+// TestDatabaseURIEnv задаёт базу PostgreSQL для набора тестов; база
+// пересоздаётся перед каждым тестом, поэтому не указывайте рабочую базу.
+const TestDatabaseURIEnv = "TEST_DATABASE_URI"
+
 func TestRepository(t *testing.T) {
-	t.Skip() // проверятся интеграционным тестом на Github
+	t.Run("memory", func(t *testing.T) {
+		repotest.Run(t, func(t *testing.T) repositories.Repo {
+			return repositories.NewRepositoryMemory()
+		})
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		repotest.Run(t, func(t *testing.T) repositories.Repo {
+			dsn := "sqlite://" + filepath.Join(t.TempDir(), "gophermart.db")
+
+			m, err := utils.NewMigrate(migrations.FS, migrations.SQLite, dsn)
+			require.NoError(t, err)
+			require.NoError(t, m.Up())
+
+			srcErr, dbErr := m.Close()
+			require.NoError(t, srcErr)
+			require.NoError(t, dbErr)
+
+			repo, err := repositories.NewRepositorySQLite(dsn)
+			require.NoError(t, err)
+
+			return repo
+		})
+	})
+
+	t.Run("postgresql", func(t *testing.T) {
+		dsn := os.Getenv(TestDatabaseURIEnv)
+		if dsn == "" {
+			t.Skipf("%s is not set", TestDatabaseURIEnv)
+		}
+
+		repotest.Run(t, func(t *testing.T) repositories.Repo {
+			m, err := utils.NewMigrate(migrations.FS, migrations.PostgreSQL, dsn)
+			require.NoError(t, err)
+
+			if err = m.Down(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, m.Up())
+
+			srcErr, dbErr := m.Close()
+			require.NoError(t, srcErr)
+			require.NoError(t, dbErr)
+
+			repo, err := repositories.NewRepositoryPostgreSQL(dsn, repositories.PoolConfig{})
+			require.NoError(t, err)
+
+			return repo
+		})
+	})
 }
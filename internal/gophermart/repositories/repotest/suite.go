@@ -0,0 +1,714 @@
+// Package repotest содержит общий набор тестов для реализаций
+// repositories.Repo: каждая реализация должна проходить его одинаково.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
+)
+
+// Factory возвращает пустое хранилище для очередного теста.
+type Factory func(t *testing.T) repositories.Repo
+
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo repositories.Repo)
+	}{
+		{name: "clients", test: testClients},
+		{name: "orders", test: testOrders},
+		{name: "orders batch", test: testOrdersBatch},
+		{name: "tx rollback", test: testTxRollback},
+		{name: "orders page", test: testOrdersPage},
+		{name: "tasks", test: testTasks},
+		{name: "withdrawals", test: testWithdrawals},
+		{name: "withdrawals page", test: testWithdrawalsPage},
+		{name: "outbox", test: testOutbox},
+		{name: "webhooks", test: testWebhooks},
+		{name: "statements", test: testStatements},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			t.Cleanup(func() {
+				assert.NoError(t, repo.Close())
+			})
+
+			require.NoError(t, repo.Ping(context.Background()))
+
+			tt.test(t, repo)
+		})
+	}
+}
+
+func newClient(t *testing.T, repo repositories.Repo, login string) models.Client {
+	t.Helper()
+
+	clientID, err := repo.SaveClient(context.Background(), models.Client{Login: login, Password: "password"})
+	require.NoError(t, err)
+
+	return models.Client{ID: clientID, Login: login}
+}
+
+func newOrder(t *testing.T, repo repositories.Repo, client models.Client, number string) models.Order {
+	t.Helper()
+
+	order := models.Order{ClientID: client.ID, Number: number}
+
+	created, err := services.NewOrderService(repo).Upload(context.Background(), &order)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	return order
+}
+
+// processOrder доводит заказ до PROCESSED с начислением accrual.
+func processOrder(t *testing.T, repo repositories.Repo, order models.Order, accrual float64) {
+	t.Helper()
+
+	require.NoError(t, services.NewOrderService(repo).ApplyAccrual(context.Background(), order.Number, string(models.StatusProcessed), accrual))
+}
+
+func withdraw(t *testing.T, repo repositories.Repo, withdrawal *models.Withdrawal) error {
+	t.Helper()
+
+	saved, err := services.NewBalanceService(repo).Withdraw(context.Background(), *withdrawal)
+	*withdrawal = saved
+
+	return err
+}
+
+func testClients(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	clientID, err := repo.SaveClient(ctx, models.Client{Login: "user", Password: "password"})
+	require.NoError(t, err)
+	assert.Positive(t, clientID)
+
+	tests := []struct {
+		name     string
+		save     bool
+		client   models.Client
+		clientID int
+		err      error
+	}{
+		{
+			name:   "case 1",
+			save:   true,
+			client: models.Client{Login: "user", Password: "other"},
+			err:    repositories.ErrLoginIsAlreadyTaken,
+		},
+		{
+			name:     "case 2",
+			client:   models.Client{Login: "user", Password: "password"},
+			clientID: clientID,
+		},
+		{
+			name:   "case 3",
+			client: models.Client{Login: "user", Password: "other"},
+			err:    repositories.ErrInvalidLoginPasswordPair,
+		},
+		{
+			name:   "case 4",
+			client: models.Client{Login: "unknown", Password: "password"},
+			err:    repositories.ErrInvalidLoginPasswordPair,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id int
+
+			if tt.save {
+				id, err = repo.SaveClient(ctx, tt.client)
+			} else {
+				id, err = repo.FindClient(ctx, tt.client)
+			}
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.clientID, id)
+		})
+	}
+}
+
+func testOrders(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	first := newClient(t, repo, "first")
+	second := newClient(t, repo, "second")
+
+	order := models.Order{ClientID: first.ID, Number: "12345678903", Amount: 1000, StoreID: "store-1"}
+	created, err := services.NewOrderService(repo).Upload(ctx, &order)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Positive(t, order.ID)
+	assert.Equal(t, models.StatusNew, order.Status)
+	assert.NotEmpty(t, order.UploadedAt)
+
+	other := newOrder(t, repo, first, "79927398713")
+
+	tests := []struct {
+		name  string
+		order models.Order
+		err   error
+	}{
+		{
+			name:  "case 1",
+			order: models.Order{ClientID: first.ID, Number: "12345678903"},
+			err:   repositories.ErrOrderNumberUploadedThisClient,
+		},
+		{
+			name:  "case 2",
+			order: models.Order{ClientID: second.ID, Number: "12345678903"},
+			err:   repositories.ErrOrderNumberUploadedAnotherClient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.InTx(ctx, func(tx repositories.Tx) error {
+				return tx.InsertOrder(ctx, &tt.order)
+			})
+
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	found, err := repo.FindOrder(ctx, first, order.Number)
+	assert.NoError(t, err)
+	assert.Equal(t, order.ID, found.ID)
+	assert.Equal(t, first.ID, found.ClientID)
+	assert.Equal(t, 1000.0, found.Amount)
+	assert.Equal(t, "store-1", found.StoreID)
+	assert.Equal(t, models.StatusNew, found.Status)
+
+	_, err = repo.FindOrder(ctx, second, order.Number)
+	assert.ErrorIs(t, err, repositories.ErrOrderNotFound)
+
+	found, err = repo.FindOrderByID(ctx, first, other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, other.Number, found.Number)
+
+	_, err = repo.FindOrderByID(ctx, second, other.ID)
+	assert.ErrorIs(t, err, repositories.ErrOrderNotFound)
+
+	orders, err := repo.FindOrders(ctx, first)
+	assert.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.Equal(t, order.Number, orders[0].Number)
+	assert.Equal(t, other.Number, orders[1].Number)
+
+	orders, err = repo.FindOrders(ctx, second)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+
+	orders, err = repo.FindOrdersByNumbers(ctx, first, []string{other.Number, "2377225624"})
+	assert.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, other.ID, orders[0].ID)
+
+	events, err := repo.FindOrderEvents(ctx, order)
+	assert.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.OrderEventUploaded, events[0].Event)
+	assert.Empty(t, events[0].StatusFrom)
+	assert.Equal(t, models.StatusNew, events[0].Status)
+
+	eventsByOrder, err := repo.FindOrdersEvents(ctx, []int{order.ID, other.ID})
+	assert.NoError(t, err)
+	assert.Len(t, eventsByOrder, 2)
+	assert.Len(t, eventsByOrder[other.ID], 1)
+}
+
+func testOrdersBatch(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	first := newClient(t, repo, "first")
+	second := newClient(t, repo, "second")
+
+	newOrder(t, repo, first, "12345678903")
+	newOrder(t, repo, second, "79927398713")
+
+	results, err := services.NewOrderService(repo).UploadBatch(ctx, first, []string{"12345678903", "79927398713", "2377225624"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.OrderUploadResult{
+		{Number: "12345678903", Result: models.OrderUploadAlreadyUploaded},
+		{Number: "79927398713", Result: models.OrderUploadConflict},
+		{Number: "2377225624", Result: models.OrderUploadAccepted},
+	}, results)
+
+	_, err = repo.FindOrder(ctx, first, "2377225624")
+	assert.NoError(t, err)
+}
+
+// testTxRollback проверяет, что ошибка внутри InTx отменяет все изменения
+// транзакции.
+func testTxRollback(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	client := newClient(t, repo, "user")
+	order := newOrder(t, repo, client, "12345678903")
+
+	errRollback := errors.New("rollback")
+
+	err := repo.InTx(ctx, func(tx repositories.Tx) error {
+		if err := tx.InsertOrder(ctx, &models.Order{ClientID: client.ID, Number: "79927398713"}); err != nil {
+			return err
+		}
+
+		if err := tx.UpdateTask(ctx, models.Task{OrderID: order.ID, Status: models.StatusProcessed, Accrual: 100}); err != nil {
+			return err
+		}
+
+		if err := tx.SaveOrderEvent(ctx, order.ID, models.OrderEvent{Event: models.OrderEventFinal, StatusFrom: models.StatusNew, Status: models.StatusProcessed}); err != nil {
+			return err
+		}
+
+		if err := tx.InsertWithdrawal(ctx, &models.Withdrawal{ClientID: client.ID, Order: "2377225624", Sum: 50}); err != nil {
+			return err
+		}
+
+		if err := tx.SaveOutboxEvent(ctx, client.ID, models.EventPointsCredited, models.PointsCredited{Order: order.Number, Accrual: 100}); err != nil {
+			return err
+		}
+
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+
+	_, err = repo.FindOrder(ctx, client, "79927398713")
+	assert.ErrorIs(t, err, repositories.ErrOrderNotFound)
+
+	found, err := repo.FindOrder(ctx, client, order.Number)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusNew, found.Status)
+
+	events, err := repo.FindOrderEvents(ctx, order)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	balance, err := repo.FindBalance(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Balace{}, balance)
+
+	outbox, err := repo.FindOutboxEvents(ctx, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, outbox, 1)
+}
+
+func testOrdersPage(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	client := newClient(t, repo, "user")
+
+	numbers := []string{"12345678903", "79927398713", "2377225624"}
+	for _, number := range numbers {
+		newOrder(t, repo, client, number)
+	}
+
+	processOrder(t, repo, newOrder(t, repo, newClient(t, repo, "other"), "4561261212345467"), 10)
+
+	page, err := repo.FindOrdersPage(ctx, client, models.ListQuery{Limit: 2})
+	assert.NoError(t, err)
+	require.Len(t, page.Orders, 2)
+	require.NotNil(t, page.Next)
+	assert.Equal(t, numbers[:2], []string{page.Orders[0].Number, page.Orders[1].Number})
+
+	page, err = repo.FindOrdersPage(ctx, client, models.ListQuery{Limit: 2, After: page.Next})
+	assert.NoError(t, err)
+	require.Len(t, page.Orders, 1)
+	assert.Nil(t, page.Next)
+	assert.Equal(t, numbers[2], page.Orders[0].Number)
+
+	page, err = repo.FindOrdersPage(ctx, client, models.ListQuery{Limit: 1, Desc: true})
+	assert.NoError(t, err)
+	require.Len(t, page.Orders, 1)
+	assert.Equal(t, numbers[2], page.Orders[0].Number)
+
+	page, err = repo.FindOrdersPage(ctx, client, models.ListQuery{Limit: 10, Statuses: []models.OrderStatus{models.StatusProcessed}})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Orders)
+
+	page, err = repo.FindOrdersPage(ctx, client, models.ListQuery{Limit: 10, To: time.Now().Add(-time.Hour)})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Orders)
+}
+
+func testTasks(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	client := newClient(t, repo, "user")
+	order := newOrder(t, repo, client, "12345678903")
+	orders := services.NewOrderService(repo)
+
+	tasks, err := orders.Tasks(ctx, models.StatusNew)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{OrderID: order.ID, ClientID: client.ID, OrderNumber: order.Number}}, tasks)
+
+	// Взятие в обработку публикуется в outbox вместе со сменой статуса.
+	outbox, err := repo.FindOutboxEvents(ctx, 0, 10)
+	assert.NoError(t, err)
+	require.Len(t, outbox, 2)
+	assert.Equal(t, models.EventOrderStatusChanged, outbox[1].Type)
+	assert.Equal(t, client.ID, outbox[1].ClientID)
+	assert.JSONEq(t, `{"number":"12345678903","status_from":"NEW","status":"PROCESSING"}`, string(outbox[1].Payload))
+
+	// Выданные задачи переводятся в обработку и больше не считаются новыми.
+	tasks, err = orders.Tasks(ctx, models.StatusNew)
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	task, err := repo.FindTask(ctx, order.Number)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Task{OrderID: order.ID, ClientID: client.ID, OrderNumber: order.Number, Status: models.StatusProcessing}, task)
+
+	tests := []struct {
+		name    string
+		number  string
+		status  models.OrderStatus
+		accrual float64
+		err     error
+	}{
+		{
+			name:   "case 1",
+			number: order.Number,
+			status: models.StatusProcessing,
+		},
+		{
+			name:    "case 2",
+			number:  order.Number,
+			status:  models.StatusProcessed,
+			accrual: 500,
+		},
+		{
+			name:    "case 3",
+			number:  order.Number,
+			status:  models.StatusProcessed,
+			accrual: 500,
+		},
+		{
+			name:   "case 4",
+			number: order.Number,
+			status: models.StatusProcessing,
+			err:    models.ErrIllegalStatusTransition,
+		},
+		{
+			name:   "case 5",
+			number: "79927398713",
+			status: models.StatusProcessed,
+			err:    repositories.ErrOrderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, orders.ApplyAccrual(ctx, tt.number, string(tt.status), tt.accrual), tt.err)
+		})
+	}
+
+	_, err = repo.FindTask(ctx, "79927398713")
+	assert.ErrorIs(t, err, repositories.ErrOrderNotFound)
+
+	// Ответ без смены статуса остаётся в истории, повторный финальный — нет.
+	events, err := repo.FindOrderEvents(ctx, order)
+	assert.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, models.OrderEventPickedUp, events[1].Event)
+	assert.Equal(t, models.OrderEvent{
+		Event:      models.OrderEventAccrual,
+		StatusFrom: models.StatusProcessing,
+		Status:     models.StatusProcessing,
+		CreatedAt:  events[2].CreatedAt,
+	}, events[2])
+	assert.Equal(t, models.OrderEvent{
+		Event:      models.OrderEventFinal,
+		StatusFrom: models.StatusProcessing,
+		Status:     models.StatusProcessed,
+		Accrual:    500,
+		CreatedAt:  events[3].CreatedAt,
+	}, events[3])
+
+	// В outbox попадают только изменения.
+	outbox, err = repo.FindOutboxEvents(ctx, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, outbox, 4)
+
+	balance, err := repo.FindBalance(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Balace{Current: 500}, balance)
+}
+
+func testWithdrawals(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	client := newClient(t, repo, "user")
+	processOrder(t, repo, newOrder(t, repo, client, "12345678903"), 100.5)
+
+	withdrawal := models.Withdrawal{ClientID: client.ID, Order: "2377225624", Sum: 40}
+	require.NoError(t, withdraw(t, repo, &withdrawal))
+	assert.Positive(t, withdrawal.ID)
+	assert.NotEmpty(t, withdrawal.ProcessedAt)
+
+	err := withdraw(t, repo, &models.Withdrawal{ClientID: client.ID, Order: "2377225624", Sum: 70})
+	assert.ErrorIs(t, err, repositories.ErrThereAreNotEnoughAccrual)
+
+	balance, err := repo.FindBalance(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Balace{Current: 60.5, Withdrawn: 40}, balance)
+
+	balance, err = repo.FindBalanceAt(ctx, client, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Balace{Current: 60.5, Withdrawn: 40}, balance)
+
+	balance, err = repo.FindBalanceAt(ctx, client, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Balace{}, balance)
+
+	withdrawals, err := repo.FindWithdrawals(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Withdrawal{{Order: "2377225624", Sum: 40, ProcessedAt: withdrawal.ProcessedAt}}, withdrawals)
+
+	entries := make([]models.StatementEntry, 0)
+
+	err = repo.StreamStatement(ctx, client, time.Time{}, time.Time{}, func(entry models.StatementEntry) error {
+		entries = append(entries, entry)
+
+		return nil
+	})
+	assert.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.StatementEntry{At: entries[0].At, Type: models.StatementAccrual, Order: "12345678903", Amount: 100.5, Balance: 100.5}, entries[0])
+	assert.Equal(t, models.StatementEntry{At: entries[1].At, Type: models.StatementWithdrawal, Order: "2377225624", Amount: -40, Balance: 60.5}, entries[1])
+}
+
+func testWithdrawalsPage(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	client := newClient(t, repo, "user")
+	processOrder(t, repo, newOrder(t, repo, client, "12345678903"), 100)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, withdraw(t, repo, &models.Withdrawal{ClientID: client.ID, Order: "2377225624", Sum: float64(i + 1)}))
+	}
+
+	page, err := repo.FindWithdrawalsPage(ctx, client, models.ListQuery{Limit: 2, Desc: true})
+	assert.NoError(t, err)
+	require.Len(t, page.Withdrawals, 2)
+	require.NotNil(t, page.Next)
+	assert.Equal(t, []float64{3, 2}, []float64{page.Withdrawals[0].Sum, page.Withdrawals[1].Sum})
+
+	page, err = repo.FindWithdrawalsPage(ctx, client, models.ListQuery{Limit: 2, Desc: true, After: page.Next})
+	assert.NoError(t, err)
+	require.Len(t, page.Withdrawals, 1)
+	assert.Nil(t, page.Next)
+	assert.Equal(t, 1.0, page.Withdrawals[0].Sum)
+	assert.Equal(t, client.ID, page.Withdrawals[0].ClientID)
+}
+
+func testOutbox(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	client := newClient(t, repo, "user")
+
+	lastID, err := repo.FindLastOutboxEventID(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, lastID)
+
+	processOrder(t, repo, newOrder(t, repo, client, "12345678903"), 100)
+	require.NoError(t, repo.SaveOutboxEvent(ctx, client.ID, models.EventStatementGenerated, models.StatementGenerated{Period: "2022-05"}))
+
+	events, err := repo.FindOutboxEvents(ctx, 0, 10)
+	assert.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, []string{models.EventOrderUploaded, models.EventOrderStatusChanged, models.EventPointsCredited, models.EventStatementGenerated},
+		[]string{events[0].Type, events[1].Type, events[2].Type, events[3].Type})
+	assert.JSONEq(t, `{"number":"12345678903"}`, string(events[0].Payload))
+	assert.Equal(t, client.ID, events[0].ClientID)
+
+	lastID, err = repo.FindLastOutboxEventID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, events[3].ID, lastID)
+
+	uploadedID := events[0].ID
+
+	events, err = repo.FindOutboxEvents(ctx, events[1].ID, 1)
+	assert.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.EventPointsCredited, events[0].Type)
+
+	other := newClient(t, repo, "other")
+	newOrder(t, repo, other, "79927398713")
+
+	events, err = repo.FindClientOutboxEvents(ctx, client.ID, uploadedID, 10)
+	assert.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, []string{models.EventOrderStatusChanged, models.EventPointsCredited, models.EventStatementGenerated},
+		[]string{events[0].Type, events[1].Type, events[2].Type})
+
+	events, err = repo.FindClientOutboxEvents(ctx, other.ID, 0, 10)
+	assert.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, other.ID, events[0].ClientID)
+
+	_, err = repo.FindOutboxCursor(ctx, "sink")
+	assert.ErrorIs(t, err, repositories.ErrOutboxCursorNotFound)
+
+	// Курсор не сдвигается назад.
+	require.NoError(t, repo.SaveOutboxCursor(ctx, "sink", 3))
+	require.NoError(t, repo.SaveOutboxCursor(ctx, "sink", 2))
+
+	cursor, err := repo.FindOutboxCursor(ctx, "sink")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), cursor)
+}
+
+func testWebhooks(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	client := newClient(t, repo, "user")
+
+	all := models.WebhookSubscription{URL: "http://localhost/all", Secret: "secret", EventTypes: []string{}}
+	require.NoError(t, repo.SaveWebhook(ctx, &all))
+	assert.Positive(t, all.ID)
+	assert.NotEmpty(t, all.CreatedAt)
+
+	withdrawn := models.WebhookSubscription{URL: "http://localhost/withdrawn", Secret: "secret", EventTypes: []string{models.EventPointsWithdrawn}}
+	require.NoError(t, repo.SaveWebhook(ctx, &withdrawn))
+
+	subscriptions, err := repo.FindWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.WebhookSubscription{all, withdrawn}, subscriptions)
+
+	newOrder(t, repo, client, "12345678903")
+
+	events, err := repo.FindOutboxEvents(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.NoError(t, repo.EnqueueWebhookDeliveries(ctx, events[0]))
+
+	deliveries, err := repo.FindDueWebhookDeliveries(ctx, 10, time.Minute)
+	assert.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	delivery := deliveries[0]
+	assert.Equal(t, all.ID, delivery.SubscriptionID)
+	assert.Equal(t, events[0].ID, delivery.EventID)
+	assert.Equal(t, models.EventOrderUploaded, delivery.EventType)
+	assert.Equal(t, models.DeliveryPending, delivery.State)
+	assert.Equal(t, all.URL, delivery.URL)
+	assert.Equal(t, all.Secret, delivery.Secret)
+	assert.Contains(t, string(delivery.Payload), `"data":{"number":"12345678903"}`)
+
+	// Арендованная доставка не выдаётся повторно до истечения аренды.
+	deliveries, err = repo.FindDueWebhookDeliveries(ctx, 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	delivery.State = models.DeliveryDelivered
+	delivery.Attempts = 1
+	delivery.LastStatusCode = 200
+	require.NoError(t, repo.SaveWebhookDelivery(ctx, delivery))
+
+	deliveries, err = repo.FindWebhookDeliveries(ctx, all.ID, 10)
+	assert.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.DeliveryDelivered, deliveries[0].State)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, 200, deliveries[0].LastStatusCode)
+	assert.NotEmpty(t, deliveries[0].DeliveredAt)
+
+	tests := []struct {
+		name           string
+		subscriptionID int
+		count          int
+		err            error
+	}{
+		{
+			name:           "case 1",
+			subscriptionID: all.ID,
+			count:          1,
+		},
+		{
+			name:           "case 2",
+			subscriptionID: withdrawn.ID,
+		},
+		{
+			name:           "case 3",
+			subscriptionID: withdrawn.ID + 100,
+			err:            repositories.ErrWebhookNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := repo.ReplayWebhook(ctx, tt.subscriptionID, events[0].ID)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.count, count)
+		})
+	}
+
+	require.NoError(t, repo.DeleteWebhook(ctx, all.ID))
+	assert.ErrorIs(t, repo.DeleteWebhook(ctx, all.ID), repositories.ErrWebhookNotFound)
+
+	deliveries, err = repo.FindWebhookDeliveries(ctx, all.ID, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func testStatements(t *testing.T, repo repositories.Repo) {
+	ctx := context.Background()
+
+	client := newClient(t, repo, "user")
+	processOrder(t, repo, newOrder(t, repo, client, "12345678903"), 100)
+	require.NoError(t, withdraw(t, repo, &models.Withdrawal{ClientID: client.ID, Order: "2377225624", Sum: 30}))
+
+	period := models.StatementPeriod(time.Now()).AddDate(0, 1, 0)
+
+	count, err := repo.GenerateStatements(ctx, period)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = repo.GenerateStatements(ctx, period)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	statement, err := repo.FindStatement(ctx, client, period)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MonthlyStatement{
+		ID:             statement.ID,
+		ClientID:       client.ID,
+		Period:         period.Format(models.StatementPeriodLayout),
+		Accruals:       100,
+		Withdrawals:    30,
+		ClosingBalance: 70,
+		CreatedAt:      statement.CreatedAt,
+	}, statement)
+
+	_, err = repo.FindStatement(ctx, client, period.AddDate(0, -1, 0))
+	assert.ErrorIs(t, err, repositories.ErrStatementNotFound)
+
+	statements, err := repo.FindStatements(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.MonthlyStatement{statement}, statements)
+
+	statements, err = repo.FindUnnotifiedStatements(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.MonthlyStatement{statement}, statements)
+
+	require.NoError(t, repo.MarkStatementNotified(ctx, statement.ID))
+
+	statements, err = repo.FindUnnotifiedStatements(ctx, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, statements)
+}
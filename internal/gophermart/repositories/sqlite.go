@@ -0,0 +1,391 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+
+	// Register SQLite driver
+	_ "modernc.org/sqlite"
+)
+
+// sqliteTimeLayout — время в UTC фиксированной ширины, чтобы строки
+// сравнивались и сортировались так же, как timestamptz.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// RepoSQLite повторяет семантику RepoPostgreSQL для небольших магазинов и
+// демонстраций. Все запросы идут через одно соединение, поэтому транзакции
+// выполняются строго по очереди: это заменяет FOR UPDATE при выдаче задач
+// и проверке баланса при списании.
+type RepoSQLite struct {
+	db *sql.DB
+}
+
+// NewRepositorySQLite открывает базу по адресу вида sqlite://path/to/file.db.
+func NewRepositorySQLite(uri string) (repo *RepoSQLite, err error) {
+	dsn, err := sqliteDSN(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	return &RepoSQLite{db: db}, nil
+}
+
+func (repo *RepoSQLite) PoolStats() models.PoolStats {
+	return poolStats(repo.db)
+}
+
+func sqliteDSN(uri string) (string, error) {
+	path := strings.TrimPrefix(uri, "sqlite://")
+
+	query := ""
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", err
+	}
+
+	values.Add("_pragma", "foreign_keys(1)")
+	values.Add("_pragma", "busy_timeout(5000)")
+
+	return path + "?" + values.Encode(), nil
+}
+
+func sqliteTime(value time.Time) string {
+	return value.UTC().Format(sqliteTimeLayout)
+}
+
+func sqliteNow() string {
+	return sqliteTime(time.Now())
+}
+
+// sqliteList передаёт список значений одним параметром, который
+// разворачивается в запросе через json_each.
+func sqliteList(values interface{}) (string, error) {
+	data, err := json.Marshal(values)
+
+	return string(data), err
+}
+
+func (repo *RepoSQLite) SaveClient(ctx context.Context, client models.Client) (clientID int, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	passwordHash := sha256.Sum256([]byte(client.Password))
+
+	err = repo.db.QueryRowContext(ctx,
+		`INSERT INTO clients (login, password) VALUES($1, $2) ON CONFLICT (login) DO NOTHING RETURNING client_id`,
+		client.Login, hex.EncodeToString(passwordHash[:])).Scan(&clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrLoginIsAlreadyTaken
+	}
+
+	return clientID, err
+}
+
+func (repo *RepoSQLite) FindClient(ctx context.Context, client models.Client) (clientID int, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	passwordHash := sha256.Sum256([]byte(client.Password))
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT client_id FROM clients WHERE login = $1 and password = $2`,
+		client.Login, hex.EncodeToString(passwordHash[:])).Scan(&clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidLoginPasswordPair
+	}
+
+	return clientID, err
+}
+
+func (repo *RepoSQLite) FindOrders(ctx context.Context, client models.Client) (orders []models.Order, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		"SELECT order_number, accrual, status, uploaded_at FROM orders WHERE client_id = $1 ORDER BY uploaded_at, order_id",
+		client.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	orders = make([]models.Order, 0)
+
+	for rows.Next() {
+		order := models.Order{}
+
+		err = rows.Scan(&order.Number, &order.Accrual, &order.Status, &order.UploadedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, err
+}
+
+func (repo *RepoSQLite) FindOrder(ctx context.Context, client models.Client, number string) (order models.Order, err error) {
+	if repo.db == nil {
+		return order, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT `+orderColumns+` FROM orders WHERE client_id = $1 AND order_number = $2`,
+		client.ID, number).Scan(orderFields(&order)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return order, ErrOrderNotFound
+	}
+
+	return order, err
+}
+
+func (repo *RepoSQLite) FindOrderByID(ctx context.Context, client models.Client, id int) (order models.Order, err error) {
+	if repo.db == nil {
+		return order, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT `+orderColumns+` FROM orders WHERE client_id = $1 AND order_id = $2`,
+		client.ID, id).Scan(orderFields(&order)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return order, ErrOrderNotFound
+	}
+
+	return order, err
+}
+
+func (repo *RepoSQLite) FindOrderEvents(ctx context.Context, order models.Order) (events []models.OrderEvent, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT event, COALESCE(status_from, ''), status_to, COALESCE(accrual, 0), created_at FROM order_events WHERE order_id = $1 ORDER BY created_at, event_id`,
+		order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events = make([]models.OrderEvent, 0)
+
+	for rows.Next() {
+		event := models.OrderEvent{}
+
+		err = rows.Scan(&event.Event, &event.StatusFrom, &event.Status, &event.Accrual, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return events, err
+}
+
+func (repo *RepoSQLite) FindOrdersByNumbers(ctx context.Context, client models.Client, numbers []string) (orders []models.Order, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	values, err := sqliteList(numbers)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT `+orderColumns+` FROM orders WHERE client_id = $1 AND order_number IN (SELECT value FROM json_each($2))`,
+		client.ID, values)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	orders = make([]models.Order, 0, len(numbers))
+
+	for rows.Next() {
+		order := models.Order{}
+
+		err = rows.Scan(orderFields(&order)...)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, err
+}
+
+func (repo *RepoSQLite) FindOrdersEvents(ctx context.Context, orderIDs []int) (events map[int][]models.OrderEvent, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	ids, err := sqliteList(orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT order_id, event, COALESCE(status_from, ''), status_to, COALESCE(accrual, 0), created_at FROM order_events
+		WHERE order_id IN (SELECT value FROM json_each($1)) ORDER BY order_id, created_at, event_id`,
+		ids)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events = make(map[int][]models.OrderEvent, len(orderIDs))
+
+	for rows.Next() {
+		var orderID int
+
+		event := models.OrderEvent{}
+
+		err = rows.Scan(&orderID, &event.Event, &event.StatusFrom, &event.Status, &event.Accrual, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		events[orderID] = append(events[orderID], event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return events, err
+}
+
+func (repo *RepoSQLite) FindWithdrawals(ctx context.Context, client models.Client) (withdrawals []models.Withdrawal, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		"SELECT order_number, sum, processed_at FROM withdrawals WHERE client_id = $1 ORDER BY processed_at, withdrawal_id",
+		client.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	withdrawals = make([]models.Withdrawal, 0)
+
+	for rows.Next() {
+		withdrawal := models.Withdrawal{}
+
+		err = rows.Scan(&withdrawal.Order, &withdrawal.Sum, &withdrawal.ProcessedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		withdrawals = append(withdrawals, withdrawal)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawals, err
+}
+
+func (repo *RepoSQLite) FindBalance(ctx context.Context, client models.Client) (balance *models.Balace, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	balance = &models.Balace{}
+
+	accurals := float64(0)
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT COALESCE((SELECT sum(accrual) FROM orders WHERE status = 'PROCESSED' AND client_id = $1), 0) as accruals,
+				COALESCE((SELECT sum(sum) FROM withdrawals WHERE client_id = $1), 0) as withdrawn`,
+		client.ID).Scan(&accurals, &balance.Withdrawn)
+	if err != nil {
+		return nil, err
+	}
+
+	balance.Current = accurals - balance.Withdrawn
+
+	return balance, err
+}
+
+func (repo *RepoSQLite) FindTask(ctx context.Context, orderNumber string) (task models.Task, err error) {
+	if repo.db == nil {
+		return task, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT order_id, client_id, order_number, accrual, status FROM orders WHERE order_number = $1`,
+		orderNumber).Scan(&task.OrderID, &task.ClientID, &task.OrderNumber, &task.Accrual, &task.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return task, ErrOrderNotFound
+	}
+
+	return task, err
+}
+
+func (repo *RepoSQLite) Close() error {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	return repo.db.Close()
+}
+
+func (repo *RepoSQLite) Ping(ctx context.Context) error {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	return repo.db.PingContext(ctx)
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func sqliteSaveOutboxEvent(ctx context.Context, tx execer, clientID int, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO outbox (client_id, event_type, payload, created_at) VALUES($1, $2, $3, $4)`,
+		clientID, eventType, string(data), sqliteNow())
+
+	return err
+}
+
+func (repo *RepoSQLite) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	return sqliteSaveOutboxEvent(ctx, repo.db, clientID, eventType, payload)
+}
+
+func (repo *RepoSQLite) FindOutboxEvents(ctx context.Context, afterEventID int64, limit int) (outboxEvents []models.OutboxEvent, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT event_id, client_id, event_type, payload, created_at FROM outbox WHERE event_id > $1 ORDER BY event_id LIMIT $2`,
+		afterEventID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOutboxEvents(rows)
+}
+
+func (repo *RepoSQLite) FindClientOutboxEvents(ctx context.Context, clientID int, afterEventID int64, limit int) (outboxEvents []models.OutboxEvent, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT event_id, client_id, event_type, payload, created_at FROM outbox
+		WHERE client_id = $1 AND event_id > $2 ORDER BY event_id LIMIT $3`,
+		clientID, afterEventID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOutboxEvents(rows)
+}
+
+func (repo *RepoSQLite) FindLastOutboxEventID(ctx context.Context) (eventID int64, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT COALESCE(max(event_id), 0) FROM outbox`).Scan(&eventID)
+
+	return eventID, err
+}
+
+func (repo *RepoSQLite) FindOutboxCursor(ctx context.Context, sink string) (eventID int64, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT event_id FROM outbox_cursors WHERE sink = $1`,
+		sink).Scan(&eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrOutboxCursorNotFound
+	}
+
+	return eventID, err
+}
+
+func (repo *RepoSQLite) SaveOutboxCursor(ctx context.Context, sink string, eventID int64) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`INSERT INTO outbox_cursors (sink, event_id, updated_at) VALUES($1, $2, $3)
+		ON CONFLICT (sink) DO UPDATE SET event_id = max(outbox_cursors.event_id, excluded.event_id), updated_at = excluded.updated_at`,
+		sink, eventID, sqliteNow())
+
+	return err
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func sqlitePageQuery() *pageQuery {
+	return &pageQuery{timeArg: func(value time.Time) interface{} { return sqliteTime(value) }}
+}
+
+func (repo *RepoSQLite) FindOrdersPage(ctx context.Context, client models.Client, query models.ListQuery) (page models.OrdersPage, err error) {
+	if repo.db == nil {
+		return page, ErrNoDBConn
+	}
+
+	q := sqlitePageQuery()
+	q.where("client_id = " + q.arg(client.ID))
+
+	if len(query.Statuses) > 0 {
+		statuses, err := sqliteList(query.Statuses)
+		if err != nil {
+			return page, err
+		}
+
+		q.where("status IN (SELECT value FROM json_each(" + q.arg(statuses) + "))")
+	}
+
+	if query.MinAccrual > 0 {
+		q.where("accrual >= " + q.arg(query.MinAccrual))
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		q.build(query, "SELECT "+orderColumns+" FROM orders", "uploaded_at", "order_id"),
+		q.args...)
+	if err != nil {
+		return page, err
+	}
+
+	defer rows.Close()
+
+	page.Orders = make([]models.Order, 0, query.Limit)
+
+	for rows.Next() {
+		order := models.Order{}
+
+		err = rows.Scan(orderFields(&order)...)
+		if err != nil {
+			return page, err
+		}
+
+		page.Orders = append(page.Orders, order)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return page, err
+	}
+
+	if len(page.Orders) > query.Limit {
+		page.Orders = page.Orders[:query.Limit]
+		last := page.Orders[query.Limit-1]
+		page.Next, err = nextCursor(last.UploadedAt, last.ID)
+	}
+
+	return page, err
+}
+
+func (repo *RepoSQLite) FindWithdrawalsPage(ctx context.Context, client models.Client, query models.ListQuery) (page models.WithdrawalsPage, err error) {
+	if repo.db == nil {
+		return page, ErrNoDBConn
+	}
+
+	q := sqlitePageQuery()
+	q.where("client_id = " + q.arg(client.ID))
+
+	rows, err := repo.db.QueryContext(ctx,
+		q.build(query, "SELECT withdrawal_id, order_number, sum, processed_at FROM withdrawals", "processed_at", "withdrawal_id"),
+		q.args...)
+	if err != nil {
+		return page, err
+	}
+
+	defer rows.Close()
+
+	page.Withdrawals = make([]models.Withdrawal, 0, query.Limit)
+
+	for rows.Next() {
+		withdrawal := models.Withdrawal{ClientID: client.ID}
+
+		err = rows.Scan(&withdrawal.ID, &withdrawal.Order, &withdrawal.Sum, &withdrawal.ProcessedAt)
+		if err != nil {
+			return page, err
+		}
+
+		page.Withdrawals = append(page.Withdrawals, withdrawal)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return page, err
+	}
+
+	if len(page.Withdrawals) > query.Limit {
+		page.Withdrawals = page.Withdrawals[:query.Limit]
+		last := page.Withdrawals[query.Limit-1]
+		page.Next, err = nextCursor(last.ProcessedAt, last.ID)
+	}
+
+	return page, err
+}
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+// sqliteStatementSQL читает выписку частями по ключу (at, kind, id): между
+// частями соединение свободно, а в памяти лежит не больше одной части.
+const sqliteStatementSQL = `
+SELECT at, kind, order_number, amount, balance, id FROM (
+	SELECT at, kind, order_number, amount, id, sum(amount) OVER (ORDER BY at, kind, id) AS balance
+	FROM (` + ledgerSQL + `) ledger WHERE client_id = $1
+) statement
+WHERE ($2 IS NULL OR at >= $2) AND ($3 IS NULL OR at < $3)
+	AND ($4 IS NULL OR (at, kind, id) > ($4, $5, $6))
+ORDER BY at, kind, id
+LIMIT $7`
+
+const sqliteGenerateStatementsSQL = `
+INSERT OR IGNORE INTO statements (client_id, period, opening_balance, accruals, withdrawals, closing_balance, created_at)
+SELECT client_id, $1,
+	COALESCE(sum(amount) FILTER (WHERE at < $2), 0),
+	COALESCE(sum(amount) FILTER (WHERE at >= $2 AND kind = 'accrual'), 0),
+	COALESCE(-sum(amount) FILTER (WHERE at >= $2 AND kind = 'withdrawal'), 0),
+	COALESCE(sum(amount), 0),
+	$4
+FROM (` + ledgerSQL + `) ledger WHERE at < $3
+GROUP BY client_id`
+
+const sqliteSelectStatementSQL = `SELECT statement_id, client_id, substr(period, 1, 7),
+	opening_balance, accruals, withdrawals, closing_balance, created_at FROM statements`
+
+func sqliteNullTime(value time.Time) interface{} {
+	if value.IsZero() {
+		return nil
+	}
+
+	return sqliteTime(value)
+}
+
+func (repo *RepoSQLite) StreamStatement(ctx context.Context, client models.Client, from, to time.Time, fn func(models.StatementEntry) error) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	var (
+		afterAt   interface{}
+		afterKind string
+		afterID   int
+	)
+
+	for {
+		entries, lastID, err := repo.statementChunk(ctx, client, from, to, afterAt, afterKind, afterID)
+		if err != nil {
+			return err
+		}
+
+		// Соединение с базой одно, поэтому fn вызывается, когда часть уже
+		// прочитана: медленный клиент не держит соединение.
+		for _, entry := range entries {
+			if err = fn(entry); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < statementChunk {
+			return nil
+		}
+
+		last := entries[len(entries)-1]
+		afterAt, afterKind, afterID = last.At, last.Type, lastID
+	}
+}
+
+func (repo *RepoSQLite) statementChunk(ctx context.Context, client models.Client, from, to time.Time,
+	afterAt interface{}, afterKind string, afterID int,
+) (entries []models.StatementEntry, lastID int, err error) {
+	rows, err := repo.db.QueryContext(ctx, sqliteStatementSQL, client.ID, sqliteNullTime(from), sqliteNullTime(to),
+		afterAt, afterKind, afterID, statementChunk)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	entries = make([]models.StatementEntry, 0, statementChunk)
+
+	for rows.Next() {
+		entry := models.StatementEntry{}
+
+		err = rows.Scan(&entry.At, &entry.Type, &entry.Order, &entry.Amount, &entry.Balance, &lastID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, lastID, rows.Err()
+}
+
+func (repo *RepoSQLite) FindBalanceAt(ctx context.Context, client models.Client, at time.Time) (balance *models.Balace, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	balance = &models.Balace{}
+
+	err = repo.db.QueryRowContext(ctx, balanceAtSQL, client.ID, sqliteTime(at)).Scan(&balance.Current, &balance.Withdrawn)
+	if err != nil {
+		return nil, err
+	}
+
+	return balance, err
+}
+
+func (repo *RepoSQLite) GenerateStatements(ctx context.Context, period time.Time) (count int, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	result, err := repo.db.ExecContext(ctx, sqliteGenerateStatementsSQL,
+		period.Format("2006-01-02"), sqliteTime(period), sqliteTime(period.AddDate(0, 1, 0)), sqliteNow())
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	return int(affected), err
+}
+
+func (repo *RepoSQLite) FindStatements(ctx context.Context, client models.Client) (statements []models.MonthlyStatement, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		sqliteSelectStatementSQL+` WHERE client_id = $1 ORDER BY period`,
+		client.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanStatements(rows)
+}
+
+func (repo *RepoSQLite) FindStatement(ctx context.Context, client models.Client, period time.Time) (statement models.MonthlyStatement, err error) {
+	if repo.db == nil {
+		return statement, ErrNoDBConn
+	}
+
+	err = repo.db.QueryRowContext(ctx,
+		sqliteSelectStatementSQL+` WHERE client_id = $1 AND period = $2`,
+		client.ID, period.Format("2006-01-02")).Scan(&statement.ID, &statement.ClientID, &statement.Period,
+		&statement.OpeningBalance, &statement.Accruals, &statement.Withdrawals, &statement.ClosingBalance, &statement.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return statement, ErrStatementNotFound
+	}
+
+	return statement, err
+}
+
+func (repo *RepoSQLite) FindUnnotifiedStatements(ctx context.Context, limit int) (statements []models.MonthlyStatement, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		sqliteSelectStatementSQL+` WHERE notified_at IS NULL ORDER BY statement_id LIMIT $1`,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanStatements(rows)
+}
+
+func (repo *RepoSQLite) MarkStatementNotified(ctx context.Context, id int) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`UPDATE statements SET notified_at = $1 WHERE statement_id = $2`,
+		sqliteNow(), id)
+
+	return err
+}
@@ -0,0 +1,176 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/migrations"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
+	"github.com/vukit/gomac/internal/gophermart/utils"
+)
+
+func newSQLiteRepository(t *testing.T) repositories.Repo {
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "gophermart.db")
+
+	m, err := utils.NewMigrate(migrations.FS, migrations.SQLite, dsn)
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+
+	srcErr, dbErr := m.Close()
+	require.NoError(t, srcErr)
+	require.NoError(t, dbErr)
+
+	repo, err := repositories.NewRepository(dsn, repositories.PoolConfig{})
+	require.NoError(t, err)
+	require.IsType(t, &repositories.RepoSQLite{}, repo)
+
+	t.Cleanup(func() { repo.Close() })
+
+	return repo
+}
+
+func TestSQLiteWithdrawals(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepository(t)
+
+	clientID, err := repo.SaveClient(ctx, models.Client{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	orders := services.NewOrderService(repo)
+	balances := services.NewBalanceService(repo)
+
+	_, err = orders.Upload(ctx, &models.Order{ClientID: clientID, Number: "12345678903"})
+	require.NoError(t, err)
+	require.NoError(t, orders.ApplyAccrual(ctx, "12345678903", string(models.StatusProcessed), 100.5))
+
+	// Из десяти параллельных списаний по 20 баллов проходят только пять.
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		rejected int
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := balances.Withdraw(ctx, models.Withdrawal{ClientID: clientID, Order: "2377225624", Sum: 20})
+			if errors.Is(err, repositories.ErrThereAreNotEnoughAccrual) {
+				mu.Lock()
+				rejected++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 5, rejected)
+
+	balance, err := repo.FindBalance(ctx, models.Client{ID: clientID})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Balace{Current: 0.5, Withdrawn: 100}, balance)
+}
+
+func TestSQLiteTasks(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepository(t)
+
+	clientID, err := repo.SaveClient(ctx, models.Client{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	orders := services.NewOrderService(repo)
+
+	_, err = orders.UploadBatch(ctx, models.Client{ID: clientID}, []string{"12345678903", "79927398713", "2377225624"})
+	require.NoError(t, err)
+
+	// Параллельные опросы получают каждый новый заказ ровно один раз.
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		leased = make(map[int]int)
+	)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			tasks, err := orders.Tasks(ctx, models.StatusNew)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			for _, task := range tasks {
+				leased[task.OrderID]++
+			}
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Len(t, leased, 3)
+
+	for _, count := range leased {
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestSQLiteStreamStatement(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	repo := newSQLiteRepository(t)
+
+	clientID, err := repo.SaveClient(ctx, models.Client{Login: "user", Password: "password"})
+	require.NoError(t, err)
+
+	client := models.Client{ID: clientID}
+
+	orders := services.NewOrderService(repo)
+
+	for _, number := range []string{"12345678903", "9278923470"} {
+		_, err = orders.Upload(ctx, &models.Order{ClientID: clientID, Number: number})
+		require.NoError(t, err)
+		require.NoError(t, orders.ApplyAccrual(ctx, number, string(models.StatusProcessed), 100))
+	}
+
+	// Выписка длиннее одной части, которую репозиторий читает за запрос.
+	for i := 0; i < 250; i++ {
+		_, err = services.NewBalanceService(repo).Withdraw(ctx, models.Withdrawal{ClientID: clientID, Order: "2377225624", Sum: 0.5})
+		require.NoError(t, err)
+	}
+
+	// Пока fn обрабатывает строки выписки, соединение свободно для других запросов.
+	entries := make([]models.StatementEntry, 0)
+
+	err = repo.StreamStatement(ctx, client, time.Time{}, time.Time{}, func(entry models.StatementEntry) error {
+		entries = append(entries, entry)
+
+		_, err := repo.FindBalance(ctx, client)
+
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 252)
+
+	balance := float64(0)
+
+	for _, entry := range entries {
+		balance += entry.Amount
+		assert.Equal(t, balance, entry.Balance)
+	}
+
+	assert.Equal(t, float64(75), balance)
+}
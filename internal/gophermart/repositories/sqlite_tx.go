@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+// txSQLite не блокирует строки: соединение одно, и транзакции и так
+// выполняются по очереди.
+type txSQLite struct {
+	tx *sql.Tx
+}
+
+func (repo *RepoSQLite) InTx(ctx context.Context, fn func(Tx) error) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil && tx != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("tx err %w: roll back err %v", err, rbErr)
+			}
+		}
+	}()
+
+	if err = fn(txSQLite{tx: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r txSQLite) InsertOrder(ctx context.Context, order *models.Order) error {
+	now := sqliteNow()
+
+	err := r.tx.QueryRowContext(ctx,
+		`INSERT INTO orders (client_id, order_number, status, uploaded_at, amount, store_id) VALUES($1, $2, $3, $4, NULLIF($5, 0), NULLIF($6, ''))
+		ON CONFLICT (order_number) DO NOTHING RETURNING order_id`,
+		order.ClientID, order.Number, models.StatusNew, now, order.Amount, order.StoreID).Scan(&order.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		var dbClientID int
+
+		err = r.tx.QueryRowContext(ctx,
+			`SELECT client_id FROM orders WHERE order_number = $1`,
+			order.Number).Scan(&dbClientID)
+		if err != nil {
+			return err
+		}
+
+		if order.ClientID == dbClientID {
+			return ErrOrderNumberUploadedThisClient
+		}
+
+		return ErrOrderNumberUploadedAnotherClient
+	}
+
+	if err != nil {
+		return err
+	}
+
+	order.Status = models.StatusNew
+	order.UploadedAt = now
+
+	return nil
+}
+
+func (r txSQLite) SaveOrderEvent(ctx context.Context, orderID int, event models.OrderEvent) error {
+	_, err := r.tx.ExecContext(ctx,
+		`INSERT INTO order_events (order_id, event, status_from, status_to, accrual, created_at) VALUES($1, $2, NULLIF($3, ''), $4, $5, $6)`,
+		orderID, event.Event, event.StatusFrom, event.Status, event.Accrual, sqliteNow())
+
+	return err
+}
+
+func (r txSQLite) FindBalanceForUpdate(ctx context.Context, client models.Client) (*models.Balace, error) {
+	balance := &models.Balace{}
+
+	accurals := float64(0)
+
+	err := r.tx.QueryRowContext(ctx,
+		`SELECT COALESCE((SELECT sum(accrual) FROM orders WHERE status = 'PROCESSED' AND client_id = $1), 0) as accruals,
+				COALESCE((SELECT sum(sum) FROM withdrawals WHERE client_id = $1), 0) as withdrawn`,
+		client.ID).Scan(&accurals, &balance.Withdrawn)
+	if err != nil {
+		return nil, err
+	}
+
+	balance.Current = accurals - balance.Withdrawn
+
+	return balance, nil
+}
+
+func (r txSQLite) InsertWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	withdrawal.ProcessedAt = sqliteNow()
+
+	return r.tx.QueryRowContext(ctx,
+		`INSERT INTO withdrawals (client_id, order_number, sum, processed_at) VALUES($1, $2, $3, $4) RETURNING withdrawal_id`,
+		withdrawal.ClientID, withdrawal.Order, withdrawal.Sum, withdrawal.ProcessedAt).Scan(&withdrawal.ID)
+}
+
+func (r txSQLite) FindTaskForUpdate(ctx context.Context, orderNumber string) (task models.Task, err error) {
+	err = r.tx.QueryRowContext(ctx,
+		`SELECT order_id, client_id, order_number, accrual, status FROM orders WHERE order_number = $1`,
+		orderNumber).Scan(&task.OrderID, &task.ClientID, &task.OrderNumber, &task.Accrual, &task.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return task, ErrOrderNotFound
+	}
+
+	return task, err
+}
+
+func (r txSQLite) UpdateTask(ctx context.Context, task models.Task) error {
+	_, err := r.tx.ExecContext(ctx,
+		`UPDATE orders SET accrual = $1, status = $2 WHERE order_id = $3`,
+		task.Accrual, task.Status, task.OrderID)
+
+	return err
+}
+
+func (r txSQLite) FindTasks(ctx context.Context, statuses ...models.OrderStatus) (tasks []models.Task, err error) {
+	values, err := sqliteList(statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.tx.QueryContext(ctx,
+		`SELECT order_id, client_id, order_number FROM orders WHERE status IN (SELECT value FROM json_each($1)) ORDER BY order_id`,
+		values)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tasks = make([]models.Task, 0)
+
+	for rows.Next() {
+		task := models.Task{}
+
+		err = rows.Scan(&task.OrderID, &task.ClientID, &task.OrderNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (r txSQLite) PickNewTasks(ctx context.Context) (tasks []models.Task, err error) {
+	rows, err := r.tx.QueryContext(ctx,
+		`UPDATE orders SET status = 'PROCESSING' WHERE status = 'NEW' RETURNING order_id, client_id, order_number, COALESCE(accrual, 0)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPickedTasks(rows)
+}
+
+func (r txSQLite) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) error {
+	return sqliteSaveOutboxEvent(ctx, r.tx, clientID, eventType, payload)
+}
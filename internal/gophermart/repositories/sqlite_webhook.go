@@ -0,0 +1,347 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+)
+
+func (repo *RepoSQLite) SaveWebhook(ctx context.Context, subscription *models.WebhookSubscription) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	eventTypes, err := sqliteList(append([]string{}, subscription.EventTypes...))
+	if err != nil {
+		return err
+	}
+
+	subscription.CreatedAt = sqliteNow()
+
+	return repo.db.QueryRowContext(ctx,
+		`INSERT INTO webhook_subscriptions (url, secret, event_types, created_at) VALUES($1, $2, $3, $4) RETURNING subscription_id`,
+		subscription.URL, subscription.Secret, eventTypes, subscription.CreatedAt).Scan(&subscription.ID)
+}
+
+func (repo *RepoSQLite) FindWebhooks(ctx context.Context) (subscriptions []models.WebhookSubscription, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT subscription_id, url, secret, event_types, created_at FROM webhook_subscriptions ORDER BY subscription_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	subscriptions = make([]models.WebhookSubscription, 0)
+
+	for rows.Next() {
+		subscription := models.WebhookSubscription{}
+		eventTypes := ""
+
+		err = rows.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes, &subscription.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = json.Unmarshal([]byte(eventTypes), &subscription.EventTypes); err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, err
+}
+
+func (repo *RepoSQLite) DeleteWebhook(ctx context.Context, subscriptionID int) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	result, err := repo.db.ExecContext(ctx,
+		`DELETE FROM webhook_subscriptions WHERE subscription_id = $1`,
+		subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (repo *RepoSQLite) FindWebhookDeliveries(ctx context.Context, subscriptionID int, limit int) (deliveries []models.WebhookDelivery, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT d.delivery_id, d.subscription_id, d.event_id, o.event_type, d.state, d.attempts,
+				COALESCE(d.last_status_code, 0), COALESCE(d.last_error, ''), d.next_attempt_at, d.delivered_at, d.created_at
+		FROM webhook_deliveries d JOIN outbox o ON o.event_id = d.event_id
+		WHERE d.subscription_id = $1 ORDER BY d.delivery_id DESC LIMIT $2`,
+		subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries = make([]models.WebhookDelivery, 0)
+
+	for rows.Next() {
+		delivery := models.WebhookDelivery{}
+		deliveredAt := sql.NullString{}
+
+		err = rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.State,
+			&delivery.Attempts, &delivery.LastStatusCode, &delivery.LastError, &delivery.NextAttemptAt,
+			&deliveredAt, &delivery.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		delivery.DeliveredAt = deliveredAt.String
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, err
+}
+
+func (repo *RepoSQLite) ReplayWebhook(ctx context.Context, subscriptionID int, fromEventID int64) (count int, err error) {
+	if repo.db == nil {
+		return 0, ErrNoDBConn
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil && tx != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("replay webhook: tx err %w: roll back err %v", err, rbErr)
+			}
+		}
+	}()
+
+	var eventTypes string
+
+	err = tx.QueryRowContext(ctx,
+		`SELECT event_types FROM webhook_subscriptions WHERE subscription_id = $1`,
+		subscriptionID).Scan(&eventTypes)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrWebhookNotFound
+		}
+
+		return 0, err
+	}
+
+	now := sqliteNow()
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (subscription_id, event_id, next_attempt_at, created_at)
+		SELECT $1, event_id, $4, $4 FROM outbox
+		WHERE event_id >= $2 AND (json_array_length($3) = 0 OR event_type IN (SELECT value FROM json_each($3)))`,
+		subscriptionID, fromEventID, eventTypes, now)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), tx.Commit()
+}
+
+func (repo *RepoSQLite) EnqueueWebhookDeliveries(ctx context.Context, event models.OutboxEvent) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	now := sqliteNow()
+
+	_, err = repo.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (subscription_id, event_id, next_attempt_at, created_at)
+		SELECT subscription_id, $1, $3, $3 FROM webhook_subscriptions
+		WHERE json_array_length(event_types) = 0 OR EXISTS (SELECT 1 FROM json_each(event_types) WHERE value = $2)`,
+		event.ID, event.Type, now)
+
+	return err
+}
+
+// FindDueWebhookDeliveries выдаёт доставки в аренду внутри одной транзакции;
+// единственное соединение с базой заменяет FOR UPDATE SKIP LOCKED.
+func (repo *RepoSQLite) FindDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) (deliveries []models.WebhookDelivery, err error) {
+	if repo.db == nil {
+		return nil, ErrNoDBConn
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil && tx != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("find due webhook deliveries: tx err %w: roll back err %v", err, rbErr)
+			}
+		}
+	}()
+
+	now := time.Now()
+
+	ids, err := sqliteLeaseDeliveries(ctx, tx, sqliteTime(now), sqliteTime(now.Add(lease)), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err = sqliteScanDueDeliveries(ctx, tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func sqliteLeaseDeliveries(ctx context.Context, tx *sql.Tx, now, leasedUntil string, limit int) (ids string, err error) {
+	rows, err := tx.QueryContext(ctx,
+		`UPDATE webhook_deliveries SET next_attempt_at = $1
+		WHERE delivery_id IN (
+			SELECT delivery_id FROM webhook_deliveries
+			WHERE state = 'PENDING' AND next_attempt_at <= $2
+			ORDER BY next_attempt_at LIMIT $3
+		) RETURNING delivery_id`,
+		leasedUntil, now, limit)
+	if err != nil {
+		return "", err
+	}
+
+	defer rows.Close()
+
+	leased := make([]int64, 0)
+
+	for rows.Next() {
+		var id int64
+
+		if err = rows.Scan(&id); err != nil {
+			return "", err
+		}
+
+		leased = append(leased, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return "", err
+	}
+
+	return sqliteList(leased)
+}
+
+func sqliteScanDueDeliveries(ctx context.Context, tx *sql.Tx, ids string) (deliveries []models.WebhookDelivery, err error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT d.delivery_id, d.subscription_id, d.event_id, o.event_type, d.attempts, d.created_at,
+				s.url, s.secret, o.client_id, o.payload, o.created_at
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.subscription_id = d.subscription_id
+		JOIN outbox o ON o.event_id = d.event_id
+		WHERE d.delivery_id IN (SELECT value FROM json_each($1))
+		ORDER BY d.delivery_id`,
+		ids)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries = make([]models.WebhookDelivery, 0)
+
+	for rows.Next() {
+		var (
+			delivery = models.WebhookDelivery{State: models.DeliveryPending}
+			event    = models.OutboxEvent{}
+			payload  []byte
+		)
+
+		err = rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Attempts,
+			&delivery.CreatedAt, &delivery.URL, &delivery.Secret, &event.ClientID, &payload, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		event.ID = delivery.EventID
+		event.Type = delivery.EventType
+		event.Payload = payload
+
+		delivery.Payload, err = models.MarshalOutboxEvent(event)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (repo *RepoSQLite) SaveWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) (err error) {
+	if repo.db == nil {
+		return ErrNoDBConn
+	}
+
+	var nextAttemptAt interface{}
+
+	if delivery.NextAttemptAt != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, delivery.NextAttemptAt)
+		if err != nil {
+			return err
+		}
+
+		nextAttemptAt = sqliteTime(parsed)
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET state = $1, attempts = $2, last_status_code = NULLIF($3, 0), last_error = NULLIF($4, ''),
+				next_attempt_at = COALESCE($5, next_attempt_at),
+				delivered_at = CASE WHEN $1 = 'DELIVERED' THEN $6 ELSE delivered_at END
+		WHERE delivery_id = $7`,
+		delivery.State, delivery.Attempts, delivery.LastStatusCode, delivery.LastError, nextAttemptAt, sqliteNow(), delivery.ID)
+
+	return err
+}
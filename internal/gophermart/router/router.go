@@ -3,43 +3,94 @@ package router
 import (
 	"context"
 	"crypto/rand"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/jwtauth"
+	"github.com/vukit/gomac/internal/gophermart/config"
+	"github.com/vukit/gomac/internal/gophermart/events"
+	"github.com/vukit/gomac/internal/gophermart/graphqlapi"
 	"github.com/vukit/gomac/internal/gophermart/handlers"
 	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/openapi"
 	"github.com/vukit/gomac/internal/gophermart/repositories"
 )
 
-func NewRouter(ctx context.Context, repo repositories.Repo, mLogger *logger.Logger) (r chi.Router, err error) {
+const eventsHeartbeat = 15 * time.Second
+
+// NewTokenAuth создаёт ключ подписи JWT; один и тот же ключ передаётся
+// в HTTP-роутер и gRPC-сервер, чтобы токены были взаимозаменяемы.
+func NewTokenAuth() (*jwtauth.JWTAuth, error) {
 	secret, err := generateSecret(32)
 	if err != nil {
 		return nil, err
 	}
 
-	tokenAuth := jwtauth.New("HS256", secret, nil)
+	return jwtauth.New("HS256", secret, nil), nil
+}
 
+func NewRouter(ctx context.Context, mConfig *config.Config, tokenAuth *jwtauth.JWTAuth, repo repositories.Repo, bus *events.Bus, mLogger *logger.Logger) (r chi.Router, err error) {
 	r = chi.NewRouter()
 
 	r.Use(middleware.Compress(5))
 
 	h := handlers.NewHandler(tokenAuth, repo, mLogger)
 
+	graphqlHandler, err := graphqlapi.NewHandler(repo, mLogger)
+	if err != nil {
+		return nil, err
+	}
+
 	r.Get("/", h.Index)
 
+	r.Get(openapi.SpecPath, openapi.SpecHandler)
+	r.Get(openapi.DocsPath, openapi.DocsHandler)
+
 	r.Post("/api/user/register", h.Register(ctx))
 
 	r.Post("/api/user/login", h.Login(ctx))
 
+	if mConfig.AccrualWebhookSecret != "" {
+		r.Post("/api/accrual/orders", h.AccrualWebhook(ctx, []byte(mConfig.AccrualWebhookSecret)))
+	}
+
+	if mConfig.AdminToken != "" {
+		r.Route("/api/admin", func(r chi.Router) {
+			r.Use(handlers.AdminAuthenticator(mConfig.AdminToken))
+			r.Post("/webhooks", h.CreateWebhook(ctx))
+			r.Get("/webhooks", h.Webhooks(ctx))
+			r.Delete("/webhooks/{id}", h.DeleteWebhook(ctx))
+			r.Get("/webhooks/{id}/deliveries", h.WebhookDeliveries(ctx))
+			r.Post("/webhooks/{id}/replay", h.ReplayWebhook(ctx))
+			r.Get("/db/stats", h.PoolStats(ctx))
+		})
+	}
+
 	r.Group(func(r chi.Router) {
 		r.Use(jwtauth.Verifier(tokenAuth))
-		r.Use(jwtauth.Authenticator)
+		r.Use(handlers.Authenticator)
 		r.Post("/api/user/orders", h.Order(ctx))
+		r.Post("/api/user/orders/batch", h.OrdersBatch(ctx))
 		r.Get("/api/user/orders", h.Orders(ctx))
+		r.Get("/api/user/orders/{number}", h.OrderTimeline(ctx))
 		r.Get("/api/user/balance", h.Balance(ctx))
 		r.Post("/api/user/balance/withdraw", h.Withdraw(ctx))
 		r.Get("/api/user/balance/withdrawals", h.Withdrawals(ctx))
+		r.Get("/api/user/statement", h.Statement(ctx))
+		r.Get("/api/user/statements", h.MonthlyStatements(ctx))
+		r.Get("/api/user/statements/{period}", h.MonthlyStatement(ctx))
+		r.Get("/api/user/events", h.Events(ctx, bus, eventsHeartbeat))
+		r.Post("/api/graphql", graphqlHandler.ServeHTTP)
+
+		r.Route(handlers.APIv2Prefix, func(r chi.Router) {
+			r.Post("/orders", h.CreateOrderV2(ctx))
+			r.Get("/orders", h.OrdersV2(ctx))
+			r.Get("/orders/{id}", h.OrderV2(ctx))
+			r.Get("/balance", h.BalanceV2(ctx))
+			r.Post("/withdrawals", h.CreateWithdrawalV2(ctx))
+			r.Get("/withdrawals", h.WithdrawalsV2(ctx))
+		})
 	})
 
 	return r, nil
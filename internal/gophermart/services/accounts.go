@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+type AccountService struct {
+	Repo repositories.Repo
+}
+
+func NewAccountService(repo repositories.Repo) *AccountService {
+	return &AccountService{Repo: repo}
+}
+
+func (r *AccountService) Register(ctx context.Context, client models.Client) (clientID int, err error) {
+	if err = client.Validate(); err != nil {
+		return 0, err
+	}
+
+	return r.Repo.SaveClient(ctx, client)
+}
+
+func (r *AccountService) Login(ctx context.Context, client models.Client) (clientID int, err error) {
+	if err = client.Validate(); err != nil {
+		return 0, err
+	}
+
+	return r.Repo.FindClient(ctx, client)
+}
@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
+)
+
+type accountsRepo struct {
+	repositories.Repo
+	clients map[string]models.Client
+}
+
+func (r *accountsRepo) SaveClient(ctx context.Context, client models.Client) (int, error) {
+	if _, ok := r.clients[client.Login]; ok {
+		return 0, repositories.ErrLoginIsAlreadyTaken
+	}
+
+	client.ID = len(r.clients) + 1
+	r.clients[client.Login] = client
+
+	return client.ID, nil
+}
+
+func (r *accountsRepo) FindClient(ctx context.Context, client models.Client) (int, error) {
+	stored, ok := r.clients[client.Login]
+	if !ok || stored.Password != client.Password {
+		return 0, repositories.ErrInvalidLoginPasswordPair
+	}
+
+	return stored.ID, nil
+}
+
+func TestAccounts(t *testing.T) {
+	accounts := services.NewAccountService(&accountsRepo{clients: map[string]models.Client{}})
+
+	tests := []struct {
+		name     string
+		register bool
+		client   models.Client
+		clientID int
+		err      error
+	}{
+		{
+			name:     "case 1",
+			register: true,
+			client:   models.Client{Login: "user", Password: "password"},
+			clientID: 1,
+		},
+		{
+			name:     "case 2",
+			register: true,
+			client:   models.Client{Login: "user", Password: "password"},
+			err:      repositories.ErrLoginIsAlreadyTaken,
+		},
+		{
+			name:     "case 3",
+			register: true,
+			client:   models.Client{Login: "user"},
+			err:      models.ErrLoginPasswordEmpity,
+		},
+		{
+			name:     "case 4",
+			client:   models.Client{Login: "user", Password: "password"},
+			clientID: 1,
+		},
+		{
+			name:   "case 5",
+			client: models.Client{Login: "user", Password: "wrong"},
+			err:    repositories.ErrInvalidLoginPasswordPair,
+		},
+		{
+			name: "case 6",
+			err:  models.ErrLoginPasswordEmpity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				clientID int
+				err      error
+			)
+
+			if tt.register {
+				clientID, err = accounts.Register(context.Background(), tt.client)
+			} else {
+				clientID, err = accounts.Login(context.Background(), tt.client)
+			}
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.clientID, clientID)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAccrualTimeout    = 5 * time.Second
+	defaultAccrualRetryAfter = 60 * time.Second
+)
+
+var (
+	ErrInvalidAccrualAddress = errors.New("invalid accrual system address")
+	ErrOrderNotRegistered    = errors.New("order is not registered in accrual system")
+)
+
+type TooManyRequestsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string {
+	return fmt.Sprintf("accrual system: too many requests, retry after %s", e.RetryAfter)
+}
+
+type ServerError struct {
+	StatusCode int
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("accrual system: server error, status code %d", e.StatusCode)
+}
+
+type UnexpectedStatusError struct {
+	StatusCode int
+}
+
+func (e *UnexpectedStatusError) Error() string {
+	return fmt.Sprintf("accrual system: unexpected status code %d", e.StatusCode)
+}
+
+type AccrualOrder struct {
+	Order   string  `json:"order"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual"`
+}
+
+type AccrualClient interface {
+	GetOrder(ctx context.Context, number string) (AccrualOrder, error)
+}
+
+type HTTPAccrualClient struct {
+	baseURL *url.URL
+	client  *http.Client
+}
+
+func NewHTTPAccrualClient(address string, timeout time.Duration) (*HTTPAccrualClient, error) {
+	baseURL, err := parseAccrualAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if timeout <= 0 {
+		timeout = defaultAccrualTimeout
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &HTTPAccrualClient{
+		baseURL: baseURL,
+		client:  &http.Client{Transport: transport, Timeout: timeout},
+	}, nil
+}
+
+func (r *HTTPAccrualClient) GetOrder(ctx context.Context, number string) (order AccrualOrder, err error) {
+	endpoint := strings.TrimRight(r.baseURL.String(), "/") + "/api/orders/" + url.PathEscape(number)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return order, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return order, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		err = json.NewDecoder(resp.Body).Decode(&order)
+
+		return order, err
+	case resp.StatusCode == http.StatusNoContent:
+		return order, ErrOrderNotRegistered
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return order, &TooManyRequestsError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return order, &ServerError{StatusCode: resp.StatusCode}
+	default:
+		return order, &UnexpectedStatusError{StatusCode: resp.StatusCode}
+	}
+}
+
+func parseAccrualAddress(address string) (*url.URL, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return nil, ErrInvalidAccrualAddress
+	}
+
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	baseURL, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAccrualAddress, err)
+	}
+
+	if (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAccrualAddress, address)
+	}
+
+	return baseURL, nil
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+
+	return defaultAccrualRetryAfter
+}
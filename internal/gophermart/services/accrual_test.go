@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/services"
+)
+
+func TestNewHTTPAccrualClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{
+			name:    "case 1",
+			address: "http://localhost:7070",
+			wantErr: false,
+		},
+		{
+			name:    "case 2",
+			address: "accrual:8080",
+			wantErr: false,
+		},
+		{
+			name:    "case 3",
+			address: "",
+			wantErr: true,
+		},
+		{
+			name:    "case 4",
+			address: "ftp://localhost:7070",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.NewHTTPAccrualClient(tt.address, time.Second)
+			assert.Equal(t, tt.wantErr, errors.Is(err, services.ErrInvalidAccrualAddress))
+		})
+	}
+}
+
+func TestHTTPAccrualClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/orders/12345678903":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"order":"12345678903","status":"PROCESSED","accrual":500}`))
+		case "/api/orders/2377225624":
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/orders/9278923470":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/api/orders/346436439":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := services.NewHTTPAccrualClient(server.URL, time.Second)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	order, err := client.GetOrder(ctx, "12345678903")
+	require.NoError(t, err)
+	assert.Equal(t, services.AccrualOrder{Order: "12345678903", Status: "PROCESSED", Accrual: 500}, order)
+
+	_, err = client.GetOrder(ctx, "2377225624")
+	assert.ErrorIs(t, err, services.ErrOrderNotRegistered)
+
+	var tooManyRequests *services.TooManyRequestsError
+	_, err = client.GetOrder(ctx, "9278923470")
+	require.ErrorAs(t, err, &tooManyRequests)
+	assert.Equal(t, 30*time.Second, tooManyRequests.RetryAfter)
+
+	var serverError *services.ServerError
+	_, err = client.GetOrder(ctx, "346436439")
+	require.ErrorAs(t, err, &serverError)
+	assert.Equal(t, http.StatusBadGateway, serverError.StatusCode)
+
+	var unexpectedStatus *services.UnexpectedStatusError
+	_, err = client.GetOrder(ctx, "1")
+	require.ErrorAs(t, err, &unexpectedStatus)
+	assert.Equal(t, http.StatusNotFound, unexpectedStatus.StatusCode)
+}
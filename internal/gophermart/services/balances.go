@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+type BalanceService struct {
+	Repo repositories.Repo
+}
+
+func NewBalanceService(repo repositories.Repo) *BalanceService {
+	return &BalanceService{Repo: repo}
+}
+
+// Balance возвращает текущий баланс, а для ненулевого at — баланс,
+// восстановленный на этот момент.
+func (r *BalanceService) Balance(ctx context.Context, client models.Client, at time.Time) (*models.Balace, error) {
+	if at.IsZero() {
+		return r.Repo.FindBalance(ctx, client)
+	}
+
+	return r.Repo.FindBalanceAt(ctx, client, at)
+}
+
+// Withdraw списывает баллы, если их хватает. Баланс проверяется под
+// блокировкой клиента в той же транзакции, что и запись списания.
+func (r *BalanceService) Withdraw(ctx context.Context, withdrawal models.Withdrawal) (models.Withdrawal, error) {
+	if err := withdrawal.Validate(); err != nil {
+		return withdrawal, err
+	}
+
+	err := r.Repo.InTx(ctx, func(tx repositories.Tx) error {
+		balance, err := tx.FindBalanceForUpdate(ctx, models.Client{ID: withdrawal.ClientID})
+		if err != nil {
+			return err
+		}
+
+		if balance.Current-withdrawal.Sum < 0 {
+			return repositories.ErrThereAreNotEnoughAccrual
+		}
+
+		if err = tx.InsertWithdrawal(ctx, &withdrawal); err != nil {
+			return err
+		}
+
+		return tx.SaveOutboxEvent(ctx, withdrawal.ClientID, models.EventPointsWithdrawn,
+			models.PointsWithdrawn{Order: withdrawal.Order, Sum: withdrawal.Sum})
+	})
+
+	return withdrawal, err
+}
+
+func (r *BalanceService) Withdrawals(ctx context.Context, client models.Client) ([]models.Withdrawal, error) {
+	return r.Repo.FindWithdrawals(ctx, client)
+}
+
+func (r *BalanceService) WithdrawalsPage(ctx context.Context, client models.Client, query models.ListQuery) (models.WithdrawalsPage, error) {
+	if err := query.Validate(); err != nil {
+		return models.WithdrawalsPage{}, err
+	}
+
+	return r.Repo.FindWithdrawalsPage(ctx, client, query)
+}
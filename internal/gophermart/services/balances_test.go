@@ -0,0 +1,128 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
+)
+
+// balancesRepo сам служит транзакцией: InTx передаёт его в fn.
+type balancesRepo struct {
+	repositories.Repo
+	repositories.Tx
+	current     float64
+	withdrawals []models.Withdrawal
+	outbox      []string
+	at          time.Time
+}
+
+func (r *balancesRepo) InTx(ctx context.Context, fn func(repositories.Tx) error) error {
+	return fn(r)
+}
+
+func (r *balancesRepo) FindBalance(ctx context.Context, client models.Client) (*models.Balace, error) {
+	return &models.Balace{Current: r.current, Withdrawn: r.withdrawn()}, nil
+}
+
+func (r *balancesRepo) FindBalanceAt(ctx context.Context, client models.Client, at time.Time) (*models.Balace, error) {
+	r.at = at
+
+	return &models.Balace{}, nil
+}
+
+func (r *balancesRepo) FindBalanceForUpdate(ctx context.Context, client models.Client) (*models.Balace, error) {
+	return r.FindBalance(ctx, client)
+}
+
+func (r *balancesRepo) InsertWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	r.current -= withdrawal.Sum
+	withdrawal.ID = len(r.withdrawals) + 1
+	r.withdrawals = append(r.withdrawals, *withdrawal)
+
+	return nil
+}
+
+func (r *balancesRepo) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) error {
+	r.outbox = append(r.outbox, eventType)
+
+	return nil
+}
+
+func (r *balancesRepo) FindWithdrawals(ctx context.Context, client models.Client) ([]models.Withdrawal, error) {
+	return r.withdrawals, nil
+}
+
+func (r *balancesRepo) withdrawn() (sum float64) {
+	for _, withdrawal := range r.withdrawals {
+		sum += withdrawal.Sum
+	}
+
+	return sum
+}
+
+func TestWithdraw(t *testing.T) {
+	repo := &balancesRepo{current: 500}
+	balances := services.NewBalanceService(repo)
+
+	tests := []struct {
+		name       string
+		withdrawal models.Withdrawal
+		id         int
+		err        error
+	}{
+		{
+			name:       "case 1",
+			withdrawal: models.Withdrawal{ClientID: 1, Order: "2377225624", Sum: 300},
+			id:         1,
+		},
+		{
+			name:       "case 2",
+			withdrawal: models.Withdrawal{ClientID: 1, Order: "2377225624", Sum: 300},
+			err:        repositories.ErrThereAreNotEnoughAccrual,
+		},
+		{
+			name:       "case 3",
+			withdrawal: models.Withdrawal{ClientID: 1, Order: "2377225625", Sum: 100},
+			err:        models.ErrInvalidOrderNumberFormat,
+		},
+		{
+			name:       "case 4",
+			withdrawal: models.Withdrawal{ClientID: 1, Order: "2377225624"},
+			err:        models.ErrWrongWithdrawalSum,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withdrawal, err := balances.Withdraw(context.Background(), tt.withdrawal)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.id, withdrawal.ID)
+		})
+	}
+
+	balance, err := balances.Balance(context.Background(), models.Client{ID: 1}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Balace{Current: 200, Withdrawn: 300}, balance)
+
+	withdrawals, err := balances.Withdrawals(context.Background(), models.Client{ID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, withdrawals, 1)
+	assert.Equal(t, []string{models.EventPointsWithdrawn}, repo.outbox)
+}
+
+func TestBalanceAt(t *testing.T) {
+	repo := &balancesRepo{}
+	balances := services.NewBalanceService(repo)
+
+	at := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := balances.Balance(context.Background(), models.Client{ID: 1}, at)
+	assert.NoError(t, err)
+	assert.Equal(t, at, repo.at)
+}
@@ -1,11 +1,9 @@
 package services
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/vukit/gomac/internal/gophermart/logger"
@@ -13,70 +11,62 @@ import (
 	"github.com/vukit/gomac/internal/gophermart/repositories"
 )
 
+const defaultRetryInterval = 2 * time.Second
+
 type LoyaltyService struct {
-	Address string
-	Repo    repositories.Repo
-	Logger  *logger.Logger
+	Client        AccrualClient
+	Repo          repositories.Repo
+	Logger        *logger.Logger
+	RetryInterval time.Duration
 }
 
 func (r *LoyaltyService) EarnPoints(ctx context.Context, task models.Task) {
-	var lsData struct {
-		Order   string
-		Status  string
-		Accrual float64
-	}
-
-	client := &http.Client{}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.Address+"/api/orders/"+task.OrderNumber, &bytes.Buffer{})
-	if err != nil {
-		r.Logger.Warning(err.Error())
-
-		return
-	}
-
 	for {
-		resp, err := client.Do(req)
-		if err != nil {
-			r.Logger.Warning(err.Error())
-			time.Sleep(2 * time.Second)
+		delay := r.retryInterval()
 
-			continue
+		// Заказ мог быть обновлён через webhook системы расчёта начислений,
+		// тогда опрашивать его больше не нужно.
+		if current, err := r.Repo.FindTask(ctx, task.OrderNumber); err == nil {
+			task.Accrual = current.Accrual
+			task.Status = current.Status
 		}
 
-		statusCode := resp.StatusCode
-		if statusCode != http.StatusOK {
-			err = fmt.Errorf("loyalty service status code %d for order %s", statusCode, task.OrderNumber)
-			r.Logger.Warning(err.Error())
-			time.Sleep(2 * time.Second)
-
-			continue
+		if models.IsFinalStatus(task.Status) {
+			return
 		}
 
-		decoder := json.NewDecoder(resp.Body)
-
-		err = decoder.Decode(&lsData)
-		if err != nil {
-			resp.Body.Close()
-			r.Logger.Warning(err.Error())
-
-			continue
+		order, err := r.Client.GetOrder(ctx, task.OrderNumber)
+		if err == nil {
+			err = r.save(ctx, &task, order)
 		}
 
-		resp.Body.Close()
-
-		if task.Accrual != lsData.Accrual || task.Status != lsData.Status {
-			task.Accrual = lsData.Accrual
-			task.Status = lsData.Status
-
-			err := r.Repo.SaveTask(ctx, task)
-			if err != nil {
-				r.Logger.Warning(err.Error())
+		if err != nil {
+			var tooManyRequests *TooManyRequestsError
+			if errors.As(err, &tooManyRequests) {
+				delay = tooManyRequests.RetryAfter
 			}
+
+			r.Logger.Warning(fmt.Sprintf("order %s: %s", task.OrderNumber, err))
+		} else if models.IsFinalStatus(task.Status) {
+			return
 		}
 
-		if task.Status == "PROCESSED" || task.Status == "INVALID" {
+		select {
+		case <-ctx.Done():
 			return
+		case <-time.After(delay):
 		}
 	}
 }
+
+func (r *LoyaltyService) retryInterval() time.Duration {
+	if r.RetryInterval <= 0 {
+		return defaultRetryInterval
+	}
+
+	return r.RetryInterval
+}
+
+func (r *LoyaltyService) save(ctx context.Context, task *models.Task, order AccrualOrder) error {
+	return applyAccrual(ctx, r.Repo, task, order.Status, order.Accrual)
+}
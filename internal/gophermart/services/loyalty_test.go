@@ -1,9 +1,171 @@
 package services_test
 
 import (
+	"context"
+	"io"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
 )
 
+type fakeAccrualClient struct {
+	mu        sync.Mutex
+	responses []fakeAccrualResponse
+}
+
+type fakeAccrualResponse struct {
+	order services.AccrualOrder
+	err   error
+}
+
+func (r *fakeAccrualClient) GetOrder(ctx context.Context, number string) (services.AccrualOrder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	response := r.responses[0]
+	if len(r.responses) > 1 {
+		r.responses = r.responses[1:]
+	}
+
+	return response.order, response.err
+}
+
+// fakeRepo сам служит транзакцией: InTx передаёт его в fn.
+type fakeRepo struct {
+	repositories.Repo
+	repositories.Tx
+	mu     sync.Mutex
+	events []models.OrderEvent
+	stored map[string]models.Task
+}
+
+func (r *fakeRepo) InTx(ctx context.Context, fn func(repositories.Tx) error) error {
+	return fn(r)
+}
+
+func (r *fakeRepo) FindTaskForUpdate(ctx context.Context, number string) (models.Task, error) {
+	return r.FindTask(ctx, number)
+}
+
+func (r *fakeRepo) UpdateTask(ctx context.Context, task models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stored[task.OrderNumber] = task
+
+	return nil
+}
+
+func (r *fakeRepo) SaveOrderEvent(ctx context.Context, orderID int, event models.OrderEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+
+	return nil
+}
+
+func (r *fakeRepo) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) error {
+	return nil
+}
+
+func (r *fakeRepo) FindTask(ctx context.Context, number string) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.stored[number]
+	if !ok {
+		return task, repositories.ErrOrderNotFound
+	}
+
+	return task, nil
+}
+
 func TestLoyalty(t *testing.T) {
-	t.Skip() // проверятся интеграционным тестом на Github
+	client := &fakeAccrualClient{responses: []fakeAccrualResponse{
+		{err: services.ErrOrderNotRegistered},
+		{err: &services.TooManyRequestsError{RetryAfter: time.Millisecond}},
+		{err: &services.ServerError{StatusCode: 500}},
+		{order: services.AccrualOrder{Order: "12345678903", Status: "REGISTERED"}},
+		{order: services.AccrualOrder{Order: "12345678903", Status: "REGISTERED"}},
+		{order: services.AccrualOrder{Order: "12345678903", Status: "PROCESSING"}},
+		{order: services.AccrualOrder{Order: "12345678903", Status: "PROCESSED", Accrual: 500}},
+	}}
+	repo := &fakeRepo{stored: map[string]models.Task{
+		"12345678903": {OrderID: 1, OrderNumber: "12345678903", Status: "NEW"},
+	}}
+
+	loyaltyService := services.LoyaltyService{
+		Client:        client,
+		Repo:          repo,
+		Logger:        logger.NewLogger(io.Discard),
+		RetryInterval: time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	loyaltyService.EarnPoints(ctx, models.Task{OrderID: 1, OrderNumber: "12345678903", Status: "NEW"})
+
+	assert.Equal(t, []models.OrderEvent{
+		{Event: models.OrderEventAccrual, StatusFrom: "NEW", Status: "PROCESSING"},
+		{Event: models.OrderEventAccrual, StatusFrom: "PROCESSING", Status: "PROCESSING"},
+		{Event: models.OrderEventAccrual, StatusFrom: "PROCESSING", Status: "PROCESSING"},
+		{Event: models.OrderEventFinal, StatusFrom: "PROCESSING", Status: "PROCESSED", Accrual: 500},
+	}, repo.events)
+	assert.Equal(t, models.StatusProcessed, repo.stored["12345678903"].Status)
+}
+
+func TestLoyaltyCanceled(t *testing.T) {
+	client := &fakeAccrualClient{responses: []fakeAccrualResponse{
+		{order: services.AccrualOrder{Order: "12345678903", Status: "PROCESSING"}},
+	}}
+	repo := &fakeRepo{stored: map[string]models.Task{
+		"12345678903": {OrderID: 1, OrderNumber: "12345678903", Status: "NEW"},
+	}}
+
+	loyaltyService := services.LoyaltyService{
+		Client:        client,
+		Repo:          repo,
+		Logger:        logger.NewLogger(io.Discard),
+		RetryInterval: time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	loyaltyService.EarnPoints(ctx, models.Task{OrderID: 1, OrderNumber: "12345678903", Status: "NEW"})
+
+	// Каждый ответ сохраняется, пока заказ не завершён.
+	assert.NotEmpty(t, repo.events)
+
+	for _, event := range repo.events {
+		assert.Equal(t, models.StatusProcessing, event.Status)
+	}
+}
+
+func TestLoyaltyPushed(t *testing.T) {
+	client := &fakeAccrualClient{responses: []fakeAccrualResponse{
+		{order: services.AccrualOrder{Order: "12345678903", Status: "PROCESSING"}},
+	}}
+	repo := &fakeRepo{stored: map[string]models.Task{
+		"12345678903": {OrderID: 1, OrderNumber: "12345678903", Status: "PROCESSED", Accrual: 500},
+	}}
+
+	loyaltyService := services.LoyaltyService{
+		Client:        client,
+		Repo:          repo,
+		Logger:        logger.NewLogger(io.Discard),
+		RetryInterval: time.Millisecond,
+	}
+
+	loyaltyService.EarnPoints(context.Background(), models.Task{OrderID: 1, OrderNumber: "12345678903", Status: "NEW"})
+
+	assert.Empty(t, repo.events)
 }
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+type OrderService struct {
+	Repo repositories.Repo
+}
+
+func NewOrderService(repo repositories.Repo) *OrderService {
+	return &OrderService{Repo: repo}
+}
+
+// Upload сохраняет новый заказ. Повторная загрузка своего заказа не
+// считается ошибкой и возвращает created = false.
+func (r *OrderService) Upload(ctx context.Context, order *models.Order) (created bool, err error) {
+	if err = order.Validate(); err != nil {
+		return false, err
+	}
+
+	err = r.Repo.InTx(ctx, func(tx repositories.Tx) error {
+		return uploadOrder(ctx, tx, order)
+	})
+	if errors.Is(err, repositories.ErrOrderNumberUploadedThisClient) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// UploadBatch отмечает некорректные номера, не обращаясь к базе, а
+// остальные сохраняет одной транзакцией.
+func (r *OrderService) UploadBatch(ctx context.Context, client models.Client, numbers []string) ([]models.OrderUploadResult, error) {
+	results := make([]models.OrderUploadResult, len(numbers))
+	valid := 0
+
+	for i, number := range numbers {
+		order := models.Order{ClientID: client.ID, Number: number}
+
+		if err := order.Validate(); err != nil {
+			results[i] = models.OrderUploadResult{Number: number, Result: models.OrderUploadInvalid}
+
+			continue
+		}
+
+		valid++
+	}
+
+	if valid == 0 {
+		return results, nil
+	}
+
+	err := r.Repo.InTx(ctx, func(tx repositories.Tx) error {
+		for i, number := range numbers {
+			if results[i].Result != "" {
+				continue
+			}
+
+			order := models.Order{ClientID: client.ID, Number: number}
+
+			err := uploadOrder(ctx, tx, &order)
+
+			switch {
+			case err == nil:
+				results[i] = models.OrderUploadResult{Number: number, Result: models.OrderUploadAccepted}
+			case errors.Is(err, repositories.ErrOrderNumberUploadedThisClient):
+				results[i] = models.OrderUploadResult{Number: number, Result: models.OrderUploadAlreadyUploaded}
+			case errors.Is(err, repositories.ErrOrderNumberUploadedAnotherClient):
+				results[i] = models.OrderUploadResult{Number: number, Result: models.OrderUploadConflict}
+			default:
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// uploadOrder записывает заказ, начало его истории и событие outbox.
+func uploadOrder(ctx context.Context, tx repositories.Tx, order *models.Order) error {
+	if err := tx.InsertOrder(ctx, order); err != nil {
+		return err
+	}
+
+	err := tx.SaveOrderEvent(ctx, order.ID, models.OrderEvent{Event: models.OrderEventUploaded, Status: models.StatusNew})
+	if err != nil {
+		return err
+	}
+
+	return tx.SaveOutboxEvent(ctx, order.ClientID, models.EventOrderUploaded, models.OrderUploaded{Number: order.Number})
+}
+
+func (r *OrderService) Orders(ctx context.Context, client models.Client) ([]models.Order, error) {
+	return r.Repo.FindOrders(ctx, client)
+}
+
+func (r *OrderService) OrdersPage(ctx context.Context, client models.Client, query models.ListQuery) (models.OrdersPage, error) {
+	if err := query.Validate(); err != nil {
+		return models.OrdersPage{}, err
+	}
+
+	return r.Repo.FindOrdersPage(ctx, client, query)
+}
+
+func (r *OrderService) Find(ctx context.Context, client models.Client, number string) (models.Order, error) {
+	return r.Repo.FindOrder(ctx, client, number)
+}
+
+func (r *OrderService) Order(ctx context.Context, client models.Client, number string) (models.OrderDetails, error) {
+	order, err := r.Repo.FindOrder(ctx, client, number)
+	if err != nil {
+		return models.OrderDetails{}, err
+	}
+
+	return r.details(ctx, order)
+}
+
+func (r *OrderService) OrderByID(ctx context.Context, client models.Client, id int) (models.OrderDetails, error) {
+	order, err := r.Repo.FindOrderByID(ctx, client, id)
+	if err != nil {
+		return models.OrderDetails{}, err
+	}
+
+	return r.details(ctx, order)
+}
+
+func (r *OrderService) details(ctx context.Context, order models.Order) (models.OrderDetails, error) {
+	events, err := r.Repo.FindOrderEvents(ctx, order)
+	if err != nil {
+		return models.OrderDetails{}, err
+	}
+
+	return models.OrderDetails{Order: order, Timeline: events}, nil
+}
+
+// ApplyAccrual применяет ответ системы расчёта начислений к заказу,
+// полученный опросом или через webhook. Повторный ответ тоже сохраняется,
+// чтобы попасть в историю заказа.
+func (r *OrderService) ApplyAccrual(ctx context.Context, number, accrualStatus string, accrual float64) error {
+	return applyAccrual(ctx, r.Repo, &models.Task{OrderNumber: number}, accrualStatus, accrual)
+}
+
+func applyAccrual(ctx context.Context, repo repositories.Repo, task *models.Task, accrualStatus string, accrual float64) error {
+	status, err := models.StatusFromAccrual(accrualStatus)
+	if err != nil {
+		return err
+	}
+
+	err = repo.InTx(ctx, func(tx repositories.Tx) error {
+		return saveAccrual(ctx, tx, task.OrderNumber, status, accrual)
+	})
+	if err != nil {
+		return err
+	}
+
+	task.Accrual = accrual
+	task.Status = status
+
+	return nil
+}
+
+// saveAccrual записывает каждый ответ системы начислений в историю заказа,
+// а в outbox — только изменения. Повторный ответ для завершённого заказа
+// ничего не меняет.
+func saveAccrual(ctx context.Context, tx repositories.Tx, number string, status models.OrderStatus, accrual float64) error {
+	stored, err := tx.FindTaskForUpdate(ctx, number)
+	if err != nil {
+		return err
+	}
+
+	unchanged := stored.Status == status && stored.Accrual == accrual
+	if unchanged && models.IsFinalStatus(stored.Status) {
+		return nil
+	}
+
+	if !unchanged {
+		if err = models.ValidateTransition(stored.Status, status); err != nil {
+			return err
+		}
+
+		updated := stored
+		updated.Status = status
+		updated.Accrual = accrual
+
+		if err = tx.UpdateTask(ctx, updated); err != nil {
+			return err
+		}
+	}
+
+	err = tx.SaveOrderEvent(ctx, stored.OrderID, models.OrderEvent{
+		Event:      models.OrderEventForAccrual(status),
+		StatusFrom: stored.Status,
+		Status:     status,
+		Accrual:    accrual,
+	})
+	if err != nil || unchanged {
+		return err
+	}
+
+	err = tx.SaveOutboxEvent(ctx, stored.ClientID, models.EventOrderStatusChanged,
+		models.OrderStatusChanged{Number: stored.OrderNumber, StatusFrom: stored.Status, Status: status, Accrual: accrual})
+	if err != nil {
+		return err
+	}
+
+	if status == models.StatusProcessed && accrual > 0 {
+		return tx.SaveOutboxEvent(ctx, stored.ClientID, models.EventPointsCredited,
+			models.PointsCredited{Order: stored.OrderNumber, Accrual: accrual})
+	}
+
+	return nil
+}
+
+// Tasks возвращает заказы в статусах statuses для опроса системы расчёта
+// начислений и переводит новые заказы в обработку. Взятие в обработку
+// попадает в историю заказа и в outbox так же, как ответ системы начислений.
+func (r *OrderService) Tasks(ctx context.Context, statuses ...models.OrderStatus) ([]models.Task, error) {
+	var tasks []models.Task
+
+	err := r.Repo.InTx(ctx, func(tx repositories.Tx) error {
+		found, err := tx.FindTasks(ctx, statuses...)
+		if err != nil {
+			return err
+		}
+
+		picked, err := tx.PickNewTasks(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range picked {
+			err = tx.SaveOrderEvent(ctx, task.OrderID, models.OrderEvent{
+				Event:      models.OrderEventPickedUp,
+				StatusFrom: models.StatusNew,
+				Status:     models.StatusProcessing,
+				Accrual:    task.Accrual,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = tx.SaveOutboxEvent(ctx, task.ClientID, models.EventOrderStatusChanged, models.OrderStatusChanged{
+				Number:     task.OrderNumber,
+				StatusFrom: models.StatusNew,
+				Status:     models.StatusProcessing,
+				Accrual:    task.Accrual,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		tasks = found
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
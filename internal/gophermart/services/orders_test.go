@@ -0,0 +1,235 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
+)
+
+// ordersRepo сам служит транзакцией: InTx передаёт его в fn.
+type ordersRepo struct {
+	repositories.Repo
+	repositories.Tx
+	orders   map[string]models.Order
+	txs      int
+	inserted []string
+	events   []models.OrderEvent
+	outbox   []string
+}
+
+func (r *ordersRepo) InTx(ctx context.Context, fn func(repositories.Tx) error) error {
+	r.txs++
+
+	return fn(r)
+}
+
+func (r *ordersRepo) InsertOrder(ctx context.Context, order *models.Order) error {
+	if stored, ok := r.orders[order.Number]; ok {
+		if stored.ClientID == order.ClientID {
+			return repositories.ErrOrderNumberUploadedThisClient
+		}
+
+		return repositories.ErrOrderNumberUploadedAnotherClient
+	}
+
+	order.ID = len(r.orders) + 1
+	order.Status = models.StatusNew
+	r.orders[order.Number] = *order
+	r.inserted = append(r.inserted, order.Number)
+
+	return nil
+}
+
+func (r *ordersRepo) SaveOrderEvent(ctx context.Context, orderID int, event models.OrderEvent) error {
+	r.events = append(r.events, event)
+
+	return nil
+}
+
+func (r *ordersRepo) SaveOutboxEvent(ctx context.Context, clientID int, eventType string, payload interface{}) error {
+	r.outbox = append(r.outbox, eventType)
+
+	return nil
+}
+
+func (r *ordersRepo) FindOrder(ctx context.Context, client models.Client, number string) (models.Order, error) {
+	order, ok := r.orders[number]
+	if !ok || order.ClientID != client.ID {
+		return models.Order{}, repositories.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+func (r *ordersRepo) FindOrderEvents(ctx context.Context, order models.Order) ([]models.OrderEvent, error) {
+	return r.events, nil
+}
+
+func (r *ordersRepo) FindTask(ctx context.Context, number string) (models.Task, error) {
+	order, ok := r.orders[number]
+	if !ok {
+		return models.Task{}, repositories.ErrOrderNotFound
+	}
+
+	return models.Task{OrderID: order.ID, ClientID: order.ClientID, OrderNumber: order.Number, Status: order.Status, Accrual: order.Accrual}, nil
+}
+
+func (r *ordersRepo) FindTaskForUpdate(ctx context.Context, number string) (models.Task, error) {
+	return r.FindTask(ctx, number)
+}
+
+func (r *ordersRepo) UpdateTask(ctx context.Context, task models.Task) error {
+	order := r.orders[task.OrderNumber]
+	order.Status = task.Status
+	order.Accrual = task.Accrual
+	r.orders[task.OrderNumber] = order
+
+	return nil
+}
+
+func TestOrderUpload(t *testing.T) {
+	repo := &ordersRepo{orders: map[string]models.Order{}}
+	orders := services.NewOrderService(repo)
+
+	tests := []struct {
+		name    string
+		order   models.Order
+		created bool
+		err     error
+	}{
+		{
+			name:    "case 1",
+			order:   models.Order{ClientID: 1, Number: "12345678903"},
+			created: true,
+		},
+		{
+			name:  "case 2",
+			order: models.Order{ClientID: 1, Number: "12345678903"},
+		},
+		{
+			name:  "case 3",
+			order: models.Order{ClientID: 2, Number: "12345678903"},
+			err:   repositories.ErrOrderNumberUploadedAnotherClient,
+		},
+		{
+			name:  "case 4",
+			order: models.Order{ClientID: 1, Number: "12345678904"},
+			err:   models.ErrInvalidOrderNumberFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, err := orders.Upload(context.Background(), &tt.order)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.created, created)
+		})
+	}
+
+	assert.Len(t, repo.orders, 1)
+	assert.Len(t, repo.events, 1)
+	assert.Equal(t, []string{models.EventOrderUploaded}, repo.outbox)
+}
+
+func TestOrderUploadBatch(t *testing.T) {
+	repo := &ordersRepo{orders: map[string]models.Order{}}
+	orders := services.NewOrderService(repo)
+
+	results, err := orders.UploadBatch(context.Background(), models.Client{ID: 1}, []string{"12345678903", "1", "79927398713"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.OrderUploadResult{
+		{Number: "12345678903", Result: models.OrderUploadAccepted},
+		{Number: "1", Result: models.OrderUploadInvalid},
+		{Number: "79927398713", Result: models.OrderUploadAccepted},
+	}, results)
+
+	// Пакет без корректных номеров не доходит до репозитория.
+	results, err = orders.UploadBatch(context.Background(), models.Client{ID: 1}, []string{"1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.OrderUploadResult{{Number: "1", Result: models.OrderUploadInvalid}}, results)
+	assert.Equal(t, 1, repo.txs)
+	assert.Equal(t, []string{"12345678903", "79927398713"}, repo.inserted)
+}
+
+func TestOrderDetails(t *testing.T) {
+	repo := &ordersRepo{
+		orders: map[string]models.Order{"12345678903": {ID: 1, ClientID: 1, Number: "12345678903", Status: models.StatusNew}},
+		events: []models.OrderEvent{{Event: models.EventOrderUploaded, Status: models.StatusNew}},
+	}
+	orders := services.NewOrderService(repo)
+
+	details, err := orders.Order(context.Background(), models.Client{ID: 1}, "12345678903")
+	assert.NoError(t, err)
+	assert.Equal(t, repo.orders["12345678903"], details.Order)
+	assert.Equal(t, repo.events, details.Timeline)
+
+	_, err = orders.Order(context.Background(), models.Client{ID: 2}, "12345678903")
+	assert.ErrorIs(t, err, repositories.ErrOrderNotFound)
+}
+
+func TestApplyAccrual(t *testing.T) {
+	repo := &ordersRepo{orders: map[string]models.Order{"12345678903": {ID: 1, ClientID: 1, Number: "12345678903", Status: models.StatusNew}}}
+	orders := services.NewOrderService(repo)
+
+	tests := []struct {
+		name    string
+		number  string
+		status  string
+		accrual float64
+		saved   int
+		err     error
+	}{
+		{
+			name:   "case 1",
+			number: "12345678903",
+			status: "PROCESSING",
+			saved:  1,
+		},
+		{
+			name:   "case 2",
+			number: "12345678903",
+			status: "PROCESSING",
+			saved:  2,
+		},
+		{
+			name:    "case 3",
+			number:  "12345678903",
+			status:  "PROCESSED",
+			accrual: 500,
+			saved:   3,
+		},
+		{
+			name:   "case 4",
+			number: "79927398713",
+			status: "PROCESSED",
+			saved:  3,
+			err:    repositories.ErrOrderNotFound,
+		},
+		{
+			name:   "case 5",
+			number: "12345678903",
+			status: "UNKNOWN",
+			saved:  3,
+			err:    models.ErrUnknownOrderStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := orders.ApplyAccrual(context.Background(), tt.number, tt.status, tt.accrual)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Len(t, repo.events, tt.saved)
+		})
+	}
+
+	assert.Equal(t, models.StatusProcessed, repo.orders["12345678903"].Status)
+	assert.Equal(t, 500.0, repo.orders["12345678903"].Accrual)
+	// Повторный ответ попадает в историю, но не в outbox.
+	assert.Equal(t, []string{models.EventOrderStatusChanged, models.EventOrderStatusChanged, models.EventPointsCredited}, repo.outbox)
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+type StatementService struct {
+	Repo repositories.Repo
+}
+
+func NewStatementService(repo repositories.Repo) *StatementService {
+	return &StatementService{Repo: repo}
+}
+
+// Stream передаёт в fn строки выписки за период [from, to] по мере чтения;
+// нулевая граница периода не ограничивает.
+func (r *StatementService) Stream(ctx context.Context, client models.Client, from, to time.Time, fn func(models.StatementEntry) error) error {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return models.ErrInvalidDateRange
+	}
+
+	return r.Repo.StreamStatement(ctx, client, from, to, fn)
+}
+
+func (r *StatementService) Monthly(ctx context.Context, client models.Client) ([]models.MonthlyStatement, error) {
+	return r.Repo.FindStatements(ctx, client)
+}
+
+func (r *StatementService) Month(ctx context.Context, client models.Client, period time.Time) (models.MonthlyStatement, error) {
+	return r.Repo.FindStatement(ctx, client, period)
+}
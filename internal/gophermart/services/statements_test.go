@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
+)
+
+type statementsRepo struct {
+	repositories.Repo
+	entries []models.StatementEntry
+	streams int
+}
+
+func (r *statementsRepo) StreamStatement(ctx context.Context, client models.Client, from, to time.Time, fn func(models.StatementEntry) error) error {
+	r.streams++
+
+	for _, entry := range r.entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestStatementStream(t *testing.T) {
+	repo := &statementsRepo{entries: []models.StatementEntry{
+		{Type: models.StatementAccrual, Order: "12345678903", Amount: 500, Balance: 500},
+	}}
+	statements := services.NewStatementService(repo)
+
+	day := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		entries int
+		err     error
+	}{
+		{
+			name:    "case 1",
+			entries: 1,
+		},
+		{
+			name:    "case 2",
+			from:    day,
+			to:      day.AddDate(0, 1, 0),
+			entries: 1,
+		},
+		{
+			name:    "case 3",
+			from:    day,
+			entries: 1,
+		},
+		{
+			name: "case 4",
+			from: day.AddDate(0, 1, 0),
+			to:   day,
+			err:  models.ErrInvalidDateRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := 0
+
+			err := statements.Stream(context.Background(), models.Client{ID: 1}, tt.from, tt.to, func(models.StatementEntry) error {
+				entries++
+
+				return nil
+			})
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.entries, entries)
+		})
+	}
+
+	// Некорректный период не доходит до репозитория.
+	assert.Equal(t, 3, repo.streams)
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+type WebhookService struct {
+	Repo repositories.Repo
+}
+
+func NewWebhookService(repo repositories.Repo) *WebhookService {
+	return &WebhookService{Repo: repo}
+}
+
+// Subscribe сохраняет подписку и возвращает её без секрета: секрет нужен
+// только для подписи доставок.
+func (r *WebhookService) Subscribe(ctx context.Context, subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	if err := subscription.Validate(); err != nil {
+		return subscription, err
+	}
+
+	if err := r.Repo.SaveWebhook(ctx, &subscription); err != nil {
+		return subscription, err
+	}
+
+	return hideSecret(subscription), nil
+}
+
+func (r *WebhookService) Subscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	subscriptions, err := r.Repo.FindWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subscriptions {
+		subscriptions[i] = hideSecret(subscriptions[i])
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookService) Unsubscribe(ctx context.Context, subscriptionID int) error {
+	return r.Repo.DeleteWebhook(ctx, subscriptionID)
+}
+
+func (r *WebhookService) Deliveries(ctx context.Context, subscriptionID int, limit int) ([]models.WebhookDelivery, error) {
+	return r.Repo.FindWebhookDeliveries(ctx, subscriptionID, limit)
+}
+
+// Replay ставит в очередь подписки события outbox начиная с fromEventID и
+// возвращает их число.
+func (r *WebhookService) Replay(ctx context.Context, subscriptionID int, fromEventID int64) (int, error) {
+	return r.Repo.ReplayWebhook(ctx, subscriptionID, fromEventID)
+}
+
+func hideSecret(subscription models.WebhookSubscription) models.WebhookSubscription {
+	subscription.Secret = ""
+	if subscription.EventTypes == nil {
+		subscription.EventTypes = []string{}
+	}
+
+	return subscription
+}
@@ -0,0 +1,93 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/services"
+)
+
+type webhooksRepo struct {
+	repositories.Repo
+	subscriptions []models.WebhookSubscription
+}
+
+func (r *webhooksRepo) SaveWebhook(ctx context.Context, subscription *models.WebhookSubscription) error {
+	subscription.ID = len(r.subscriptions) + 1
+	r.subscriptions = append(r.subscriptions, *subscription)
+
+	return nil
+}
+
+func (r *webhooksRepo) FindWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	subscriptions := make([]models.WebhookSubscription, len(r.subscriptions))
+	copy(subscriptions, r.subscriptions)
+
+	return subscriptions, nil
+}
+
+func TestWebhookSubscribe(t *testing.T) {
+	repo := &webhooksRepo{}
+	webhooks := services.NewWebhookService(repo)
+
+	tests := []struct {
+		name         string
+		subscription models.WebhookSubscription
+		id           int
+		err          error
+	}{
+		{
+			name:         "case 1",
+			subscription: models.WebhookSubscription{URL: "https://example.com/hook", Secret: "secret"},
+			id:           1,
+		},
+		{
+			name:         "case 2",
+			subscription: models.WebhookSubscription{URL: "https://example.com/hook", Secret: "secret", EventTypes: []string{models.EventPointsCredited}},
+			id:           2,
+		},
+		{
+			name:         "case 3",
+			subscription: models.WebhookSubscription{URL: "ftp://example.com/hook", Secret: "secret"},
+			err:          models.ErrInvalidWebhookURL,
+		},
+		{
+			name:         "case 4",
+			subscription: models.WebhookSubscription{URL: "https://example.com/hook", Secret: " "},
+			err:          models.ErrEmptyWebhookSecret,
+		},
+		{
+			name:         "case 5",
+			subscription: models.WebhookSubscription{URL: "https://example.com/hook", Secret: "secret", EventTypes: []string{"unknown"}},
+			err:          models.ErrUnknownEventType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription, err := webhooks.Subscribe(context.Background(), tt.subscription)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.id, subscription.ID)
+
+			if err == nil {
+				assert.Empty(t, subscription.Secret)
+				assert.NotNil(t, subscription.EventTypes)
+			}
+		})
+	}
+
+	// Секрет хранится для подписи доставок, но наружу не отдаётся.
+	assert.Len(t, repo.subscriptions, 2)
+	assert.Equal(t, "secret", repo.subscriptions[0].Secret)
+
+	subscriptions, err := webhooks.Subscriptions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []models.WebhookSubscription{
+		{ID: 1, URL: "https://example.com/hook", EventTypes: []string{}},
+		{ID: 2, URL: "https://example.com/hook", EventTypes: []string{models.EventPointsCredited}},
+	}, subscriptions)
+}
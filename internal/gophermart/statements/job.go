@@ -0,0 +1,100 @@
+package statements
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+const (
+	defaultInterval = time.Hour
+	defaultBatch    = 100
+)
+
+// Job формирует выписки за прошедший месяц и рассылает уведомления о них.
+// Выписка уникальна для клиента и периода, а уведомление отмечается после
+// отправки, поэтому повторный запуск не создаёт дублей.
+type Job struct {
+	Repo     repositories.StatementRepo
+	Notifier Notifier
+	Logger   *logger.Logger
+	Interval time.Duration
+	Batch    int
+	Now      func() time.Time
+
+	generated time.Time
+}
+
+func NewJob(repo repositories.StatementRepo, notifier Notifier, mLogger *logger.Logger) *Job {
+	return &Job{
+		Repo:     repo,
+		Notifier: notifier,
+		Logger:   mLogger,
+		Interval: defaultInterval,
+		Batch:    defaultBatch,
+		Now:      time.Now,
+	}
+}
+
+func (r *Job) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Process(ctx); err != nil {
+			r.Logger.Warning(err.Error())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Job) Process(ctx context.Context) error {
+	period := models.StatementPeriod(r.Now())
+
+	if !period.Equal(r.generated) {
+		count, err := r.Repo.GenerateStatements(ctx, period)
+		if err != nil {
+			return fmt.Errorf("generate statements for %s: %w", period.Format(models.StatementPeriodLayout), err)
+		}
+
+		if count > 0 {
+			r.Logger.Info(fmt.Sprintf("generated %d statements for %s", count, period.Format(models.StatementPeriodLayout)))
+		}
+
+		r.generated = period
+	}
+
+	return r.notify(ctx)
+}
+
+func (r *Job) notify(ctx context.Context) error {
+	for {
+		statements, err := r.Repo.FindUnnotifiedStatements(ctx, r.Batch)
+		if err != nil {
+			return err
+		}
+
+		for _, statement := range statements {
+			if err = r.Notifier.Notify(ctx, statement); err != nil {
+				return fmt.Errorf("notify statement %s for client %d: %w", statement.Period, statement.ClientID, err)
+			}
+
+			if err = r.Repo.MarkStatementNotified(ctx, statement.ID); err != nil {
+				return err
+			}
+		}
+
+		if len(statements) < r.Batch {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package statements_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/statements"
+)
+
+type fakeRepo struct {
+	repositories.StatementRepo
+	clients    []int
+	statements []models.MonthlyStatement
+	notified   map[int]bool
+	generated  int
+}
+
+func (r *fakeRepo) GenerateStatements(ctx context.Context, period time.Time) (int, error) {
+	r.generated++
+
+	count := 0
+
+	for _, clientID := range r.clients {
+		exists := false
+
+		for _, statement := range r.statements {
+			if statement.ClientID == clientID && statement.Period == period.Format(models.StatementPeriodLayout) {
+				exists = true
+			}
+		}
+
+		if !exists {
+			r.statements = append(r.statements, models.MonthlyStatement{
+				ID:       len(r.statements) + 1,
+				ClientID: clientID,
+				Period:   period.Format(models.StatementPeriodLayout),
+			})
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *fakeRepo) FindUnnotifiedStatements(ctx context.Context, limit int) ([]models.MonthlyStatement, error) {
+	pending := make([]models.MonthlyStatement, 0)
+
+	for _, statement := range r.statements {
+		if !r.notified[statement.ID] && len(pending) < limit {
+			pending = append(pending, statement)
+		}
+	}
+
+	return pending, nil
+}
+
+func (r *fakeRepo) MarkStatementNotified(ctx context.Context, id int) error {
+	r.notified[id] = true
+
+	return nil
+}
+
+type fakeNotifier struct {
+	sent []models.MonthlyStatement
+	fail bool
+}
+
+func (r *fakeNotifier) Notify(ctx context.Context, statement models.MonthlyStatement) error {
+	if r.fail {
+		return errors.New("smtp is down")
+	}
+
+	r.sent = append(r.sent, statement)
+
+	return nil
+}
+
+func TestJob(t *testing.T) {
+	repo := &fakeRepo{clients: []int{1, 2, 3}, notified: map[int]bool{}}
+	notifier := &fakeNotifier{fail: true}
+	now := time.Date(2021, 1, 1, 0, 30, 0, 0, time.UTC)
+
+	job := statements.NewJob(repo, notifier, logger.NewLogger(io.Discard))
+	job.Batch = 2
+	job.Now = func() time.Time { return now }
+
+	require.Error(t, job.Process(context.Background()))
+	assert.Len(t, repo.statements, 3)
+	assert.Empty(t, notifier.sent)
+
+	notifier.fail = false
+	require.NoError(t, job.Process(context.Background()))
+	assert.Equal(t, 1, repo.generated)
+	assert.Len(t, notifier.sent, 3)
+	assert.Equal(t, "2020-12", notifier.sent[0].Period)
+
+	// Повторный запуск, например после рестарта, не создаёт дублей.
+	job = statements.NewJob(repo, notifier, logger.NewLogger(io.Discard))
+	job.Now = func() time.Time { return now.Add(time.Hour) }
+	require.NoError(t, job.Process(context.Background()))
+	assert.Equal(t, 2, repo.generated)
+	assert.Len(t, repo.statements, 3)
+	assert.Len(t, notifier.sent, 3)
+
+	now = time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	job.Now = func() time.Time { return now }
+	require.NoError(t, job.Process(context.Background()))
+	assert.Len(t, repo.statements, 6)
+	assert.Len(t, notifier.sent, 6)
+	assert.Equal(t, "2021-01", notifier.sent[5].Period)
+}
@@ -0,0 +1,53 @@
+package statements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+)
+
+var ErrUnknownNotifier = errors.New("unknown statement notifier")
+
+type Notifier interface {
+	Notify(context.Context, models.MonthlyStatement) error
+}
+
+type LogNotifier struct {
+	Logger *logger.Logger
+}
+
+func (r LogNotifier) Notify(ctx context.Context, statement models.MonthlyStatement) error {
+	r.Logger.Info(fmt.Sprintf("statement %s for client %d: opening %v, accruals %v, withdrawals %v, closing %v",
+		statement.Period, statement.ClientID, statement.OpeningBalance, statement.Accruals, statement.Withdrawals, statement.ClosingBalance))
+
+	return nil
+}
+
+// OutboxNotifier публикует событие statement.generated, которое дальше
+// доставляется синками outbox, в том числе подписчикам вебхуков.
+type OutboxNotifier struct {
+	Repo repositories.OutboxRepo
+}
+
+func (r OutboxNotifier) Notify(ctx context.Context, statement models.MonthlyStatement) error {
+	return r.Repo.SaveOutboxEvent(ctx, statement.ClientID, models.EventStatementGenerated, models.StatementGenerated{
+		Period:         statement.Period,
+		OpeningBalance: statement.OpeningBalance,
+		ClosingBalance: statement.ClosingBalance,
+	})
+}
+
+func NewNotifier(name string, repo repositories.Repo, mLogger *logger.Logger) (Notifier, error) {
+	switch name {
+	case "log":
+		return LogNotifier{Logger: mLogger}, nil
+	case "outbox":
+		return OutboxNotifier{Repo: repo}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownNotifier, name)
+	}
+}
@@ -2,17 +2,30 @@ package utils
 
 import (
 	"errors"
+	"io/fs"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/vukit/gomac/internal/gophermart/logger"
 
 	// Register packages for migration
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
 )
 
-func MigrationUp(source, dsn string, mLogger *logger.Logger) {
-	m, err := migrate.New(source, dsn)
+// NewMigrate читает миграции из каталога dir файловой системы fsys,
+// например встроенной в бинарный файл.
+func NewMigrate(fsys fs.FS, dir, dsn string) (*migrate.Migrate, error) {
+	source, err := iofs.New(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.NewWithSourceInstance("iofs", source, dsn)
+}
+
+func MigrationUp(fsys fs.FS, dir, dsn string, mLogger *logger.Logger) {
+	m, err := NewMigrate(fsys, dir, dsn)
 	if err != nil {
 		mLogger.Fatal(err.Error())
 
@@ -24,8 +37,8 @@ func MigrationUp(source, dsn string, mLogger *logger.Logger) {
 	}
 }
 
-func MigrationDown(source, dsn string, mLogger *logger.Logger) {
-	m, err := migrate.New(source, dsn)
+func MigrationDown(fsys fs.FS, dir, dsn string, mLogger *logger.Logger) {
+	m, err := NewMigrate(fsys, dir, dsn)
 	if err != nil {
 		mLogger.Fatal(err.Error())
 
@@ -0,0 +1,63 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/utils"
+)
+
+func TestSignature(t *testing.T) {
+	data := []byte(`{"order":"12345678903","status":"PROCESSED","accrual":500}`)
+	signature := utils.Sign([]byte("secret"), data)
+
+	tests := []struct {
+		name      string
+		secret    string
+		data      []byte
+		signature string
+		want      bool
+	}{
+		{
+			name:      "valid signature",
+			secret:    "secret",
+			data:      data,
+			signature: signature,
+			want:      true,
+		},
+		{
+			name:      "wrong secret",
+			secret:    "another",
+			data:      data,
+			signature: signature,
+			want:      false,
+		},
+		{
+			name:      "wrong data",
+			secret:    "secret",
+			data:      []byte(`{}`),
+			signature: signature,
+			want:      false,
+		},
+		{
+			name:      "empty secret",
+			secret:    "",
+			data:      data,
+			signature: utils.Sign(nil, data),
+			want:      false,
+		},
+		{
+			name:      "no prefix",
+			secret:    "secret",
+			data:      data,
+			signature: signature[len("sha256="):],
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, utils.ValidSignature([]byte(tt.secret), tt.data, tt.signature))
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/utils"
+)
+
+const (
+	SignatureHeader = "X-Gophermart-Signature"
+	EventHeader     = "X-Gophermart-Event"
+	DeliveryHeader  = "X-Gophermart-Delivery"
+
+	defaultInterval    = time.Second
+	defaultBatch       = 100
+	defaultMaxAttempts = 10
+	defaultBaseBackoff = 5 * time.Second
+	defaultMaxBackoff  = time.Hour
+	defaultTimeout     = 10 * time.Second
+	maxErrorLength     = 512
+)
+
+type Worker struct {
+	Repo        repositories.WebhookRepo
+	Client      *http.Client
+	Logger      *logger.Logger
+	Interval    time.Duration
+	Batch       int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+func NewWorker(repo repositories.WebhookRepo, mLogger *logger.Logger) *Worker {
+	return &Worker{
+		Repo:        repo,
+		Client:      &http.Client{Timeout: defaultTimeout},
+		Logger:      mLogger,
+		Interval:    defaultInterval,
+		Batch:       defaultBatch,
+		MaxAttempts: defaultMaxAttempts,
+		BaseBackoff: defaultBaseBackoff,
+		MaxBackoff:  defaultMaxBackoff,
+	}
+}
+
+func (r *Worker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Process(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Worker) Process(ctx context.Context) {
+	deliveries, err := r.Repo.FindDueWebhookDeliveries(ctx, r.Batch, r.Lease())
+	if err != nil {
+		r.Logger.Warning(err.Error())
+
+		return
+	}
+
+	for _, delivery := range deliveries {
+		delivery = r.Deliver(ctx, delivery)
+
+		if err = r.Repo.SaveWebhookDelivery(ctx, delivery); err != nil {
+			r.Logger.Warning(err.Error())
+		}
+	}
+}
+
+// Lease — на сколько доставки пакета закрываются от других экземпляров.
+// Пакет отправляется последовательно, поэтому аренда должна пережить
+// Batch запросов с таймаутом клиента, иначе последние строки пакета
+// заберёт и отправит повторно другой экземпляр.
+func (r *Worker) Lease() time.Duration {
+	return time.Duration(r.Batch)*r.Client.Timeout + r.Interval
+}
+
+func (r *Worker) Deliver(ctx context.Context, delivery models.WebhookDelivery) models.WebhookDelivery {
+	delivery.Attempts++
+	delivery.LastStatusCode = 0
+	delivery.LastError = ""
+
+	statusCode, err := r.post(ctx, delivery)
+
+	delivery.LastStatusCode = statusCode
+
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		delivery.State = models.DeliveryDelivered
+		delivery.NextAttemptAt = ""
+
+		return delivery
+	case err != nil:
+		delivery.LastError = truncate(err.Error())
+	default:
+		delivery.LastError = fmt.Sprintf("unexpected status code %d", statusCode)
+	}
+
+	if delivery.Attempts >= r.MaxAttempts {
+		delivery.State = models.DeliveryFailed
+		delivery.NextAttemptAt = ""
+
+		return delivery
+	}
+
+	delivery.State = models.DeliveryPending
+	delivery.NextAttemptAt = time.Now().Add(r.Backoff(delivery.Attempts)).Format(time.RFC3339Nano)
+
+	return delivery
+}
+
+func (r *Worker) Backoff(attempts int) time.Duration {
+	backoff := r.BaseBackoff
+
+	for i := 1; i < attempts && backoff < r.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > r.MaxBackoff {
+		return r.MaxBackoff
+	}
+
+	return backoff
+}
+
+func (r *Worker) post(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, utils.Sign([]byte(delivery.Secret), delivery.Payload))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	return resp.StatusCode, nil
+}
+
+func truncate(message string) string {
+	if len(message) > maxErrorLength {
+		return message[:maxErrorLength]
+	}
+
+	return message
+}
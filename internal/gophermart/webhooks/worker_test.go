@@ -0,0 +1,112 @@
+package webhooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vukit/gomac/internal/gophermart/logger"
+	"github.com/vukit/gomac/internal/gophermart/models"
+	"github.com/vukit/gomac/internal/gophermart/repositories"
+	"github.com/vukit/gomac/internal/gophermart/utils"
+	"github.com/vukit/gomac/internal/gophermart/webhooks"
+)
+
+type fakeRepo struct {
+	repositories.WebhookRepo
+	mu    sync.Mutex
+	due   []models.WebhookDelivery
+	saved []models.WebhookDelivery
+	lease time.Duration
+}
+
+func (r *fakeRepo) FindDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := r.due
+	r.due = nil
+	r.lease = lease
+
+	return due, nil
+}
+
+func (r *fakeRepo) SaveWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.saved = append(r.saved, delivery)
+
+	return nil
+}
+
+func TestWorkerProcess(t *testing.T) {
+	payload := []byte(`{"id":1,"type":"points.credited","client_id":1,"created_at":"","data":{"order":"12345678903","accrual":500}}`)
+
+	var received []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if !utils.ValidSignature([]byte("secret"), body, r.Header.Get(webhooks.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		received = append(received, r.Header.Get(webhooks.EventHeader))
+	}))
+	defer server.Close()
+
+	repo := &fakeRepo{due: []models.WebhookDelivery{
+		{ID: 1, EventType: models.EventPointsCredited, URL: server.URL, Secret: "secret", Payload: payload},
+		{ID: 2, EventType: models.EventPointsCredited, URL: server.URL, Secret: "wrong", Payload: payload},
+		{ID: 3, EventType: models.EventPointsCredited, URL: server.URL, Secret: "wrong", Payload: payload, Attempts: 9},
+	}}
+
+	worker := webhooks.NewWorker(repo, logger.NewLogger(io.Discard))
+	worker.Process(context.Background())
+
+	assert.Equal(t, []string{models.EventPointsCredited}, received)
+
+	assert.Len(t, repo.saved, 3)
+
+	assert.Equal(t, models.DeliveryDelivered, repo.saved[0].State)
+	assert.Equal(t, 1, repo.saved[0].Attempts)
+	assert.Equal(t, http.StatusOK, repo.saved[0].LastStatusCode)
+
+	assert.Equal(t, models.DeliveryPending, repo.saved[1].State)
+	assert.Equal(t, http.StatusUnauthorized, repo.saved[1].LastStatusCode)
+	assert.NotEmpty(t, repo.saved[1].LastError)
+	assert.NotEmpty(t, repo.saved[1].NextAttemptAt)
+
+	assert.Equal(t, models.DeliveryFailed, repo.saved[2].State)
+	assert.Equal(t, 10, repo.saved[2].Attempts)
+
+	// Аренда покрывает весь пакет, а не одну доставку.
+	assert.Equal(t, 100*10*time.Second+time.Second, repo.lease)
+}
+
+func TestWorkerBackoff(t *testing.T) {
+	worker := webhooks.Worker{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 5, want: 10 * time.Second},
+		{attempts: 50, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, worker.Backoff(tt.attempts))
+	}
+}